@@ -0,0 +1,144 @@
+// This program aligns two word2vec models, such as a retrained version of the
+// review model and the previous one, or the review model and a pretrained
+// model. The vectors of the source model are rotated onto the destination
+// model using the shared frequent words as anchors, and the words whose
+// vectors still differ the most are reported with their nearest neighbours
+// in both models, since those are the words whose meaning shifted.
+//
+// # Running the program:
+//
+//   $ make align SRC=zarf/data/old.model DST=zarf/data/example3.model
+//
+// The aligned source model can be written with -out and loaded as any other
+// word2vec model, its vectors being comparable with the destination model.
+//
+// # WARNING
+//
+// This program uses the same C++ based dynamic library as example3, see the
+// notes of that example on how to build it.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ardanlabs/ai-training/foundation/align"
+	"github.com/ardanlabs/ai-training/foundation/word2vec"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	fileSrc := flag.String("src", "", "word2vec model to align")
+	fileDst := flag.String("dst", "zarf/data/example3.model", "word2vec model to align to")
+	output := flag.String("out", "", "file to write the aligned source model to")
+	words := flag.Int("words", 20000, "number of most frequent words of every model compared, all when zero")
+	anchors := flag.Int("anchors", 0, "number of most frequent shared words used as anchors, all when zero")
+	top := flag.Int("top", 20, "number of most shifted words reported")
+	neighbours := flag.Int("neighbours", 5, "number of nearest neighbours shown for every shifted word")
+	flag.Parse()
+
+	if *fileSrc == "" {
+		return fmt.Errorf("no source model provided, use -src")
+	}
+
+	src, err := loadSpace(*fileSrc, *words)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+
+	dst, err := loadSpace(*fileDst, *words)
+	if err != nil {
+		return fmt.Errorf("destination: %w", err)
+	}
+
+	shared := src.Shared(dst)
+
+	anchorWords := shared
+	if *anchors > 0 && len(anchorWords) > *anchors {
+		anchorWords = anchorWords[:*anchors]
+	}
+
+	fmt.Printf("Source: %s, %d words\n", *fileSrc, src.Size())
+	fmt.Printf("Destination: %s, %d words\n", *fileDst, dst.Size())
+	fmt.Printf("Shared words: %d, anchors: %d\n\n", len(shared), len(anchorWords))
+
+	mapping, err := align.Learn(src, dst, anchorWords)
+	if err != nil {
+		return fmt.Errorf("learn mapping: %w", err)
+	}
+
+	aligned, err := mapping.Apply(src)
+	if err != nil {
+		return fmt.Errorf("apply mapping: %w", err)
+	}
+
+	if *output != "" {
+		if err := aligned.Save(*output); err != nil {
+			return fmt.Errorf("save aligned model: %w", err)
+		}
+		fmt.Printf("Aligned model: %s\n\n", *output)
+	}
+
+	shifts := align.Shifts(aligned, dst, shared)
+	if len(shifts) > *top {
+		shifts = shifts[:*top]
+	}
+
+	fmt.Println("Most shifted words:")
+
+	vec := make([]float32, dst.VectorSize())
+	for _, s := range shifts {
+		fmt.Printf("  %-20s similarity: %.4f\n", s.Word, s.Similarity)
+
+		if *neighbours <= 0 {
+			continue
+		}
+
+		aligned.VectorOf(s.Word, vec)
+		fmt.Printf("    source:      %s\n", neighbourList(aligned.Nearest(vec, *neighbours, s.Word)))
+
+		dst.VectorOf(s.Word, vec)
+		fmt.Printf("    destination: %s\n", neighbourList(dst.Nearest(vec, *neighbours, s.Word)))
+	}
+
+	return nil
+}
+
+// loadSpace reads the vectors of the most frequent words of the model. The
+// sentence delimiter is left out since it is not a word.
+func loadSpace(fileModel string, words int) (*align.Space, error) {
+	model, err := word2vec.Load(fileModel, 0)
+	if err != nil {
+		return nil, fmt.Errorf("load model: %w", err)
+	}
+	defer model.Close()
+
+	var list []string
+	for word := range model.Words() {
+		if word == "</s>" {
+			continue
+		}
+		if words > 0 && len(list) == words {
+			break
+		}
+		list = append(list, word)
+	}
+
+	return align.NewSpace(&model, list, model.VectorSize())
+}
+
+func neighbourList(nearest []align.Neighbour) string {
+	list := make([]string, len(nearest))
+	for i, n := range nearest {
+		list[i] = fmt.Sprintf("%s (%.2f)", n.Word, n.Similarity)
+	}
+	return strings.Join(list, ", ")
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pdfLayout represents what the pdftohtml tool of poppler, which docconv
+// relies on too, finds in a PDF: the outline and the lines of text with their
+// font.
+type pdfLayout struct {
+	outline []heading
+	lines   []layoutLine
+}
+
+// layoutLine represents a line of text of a page.
+type layoutLine struct {
+	page int
+	text string
+	size float64
+	bold bool
+}
+
+// readPDFLayout runs pdftohtml to get the XML description of the PDF.
+func readPDFLayout(pdfFile string) (pdfLayout, error) {
+	dir, err := os.MkdirTemp("", "cleaner")
+	if err != nil {
+		return pdfLayout{}, fmt.Errorf("temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.Command("pdftohtml", "-xml", "-i", "-q", "-nodrm", pdfFile, filepath.Join(dir, "book"))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if out = bytes.TrimSpace(out); len(out) > 0 {
+			return pdfLayout{}, fmt.Errorf("pdftohtml: %w: %s", err, out)
+		}
+		return pdfLayout{}, fmt.Errorf("pdftohtml: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "book.xml"))
+	if err != nil {
+		return pdfLayout{}, fmt.Errorf("read file: %w", err)
+	}
+
+	return parsePDFLayout(data)
+}
+
+// parsePDFLayout parses the XML of pdftohtml, where the outline is a tree of
+// nested outline elements holding item elements:
+//
+//	<page number="1">
+//	    <fontspec id="0" size="29" family="Times" color="#000000"/>
+//	    <text top="100" left="88" width="300" height="33" font="0"><b>Welcome</b></text>
+//	</page>
+//	<outline>
+//	    <item page="3">Welcome</item>
+//	    <outline>
+//	        <item page="4">Intended Audience</item>
+//	    </outline>
+//	</outline>
+func parsePDFLayout(data []byte) (pdfLayout, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	var doc pdfLayout
+	var page, depth int
+	fonts := make(map[string]float64)
+
+	// The text and bold runs of the current text or item element.
+	var inText, inItem bool
+	var text strings.Builder
+	var font string
+	var boldRunes, totalRunes int
+	var bold int
+
+	for {
+		tkn, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return pdfLayout{}, fmt.Errorf("parse xml: %w", err)
+		}
+
+		switch t := tkn.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "page":
+				page, _ = strconv.Atoi(attr(t, "number"))
+
+			case "fontspec":
+				size, _ := strconv.ParseFloat(attr(t, "size"), 64)
+				fonts[attr(t, "id")] = size
+
+			case "text":
+				inText = true
+				text.Reset()
+				font = attr(t, "font")
+				boldRunes, totalRunes = 0, 0
+
+			case "b":
+				bold++
+
+			case "outline":
+				depth++
+
+			case "item":
+				inItem = true
+				text.Reset()
+			}
+
+		case xml.CharData:
+			if inText || inItem {
+				text.Write(t)
+
+				n := len([]rune(strings.TrimSpace(string(t))))
+				totalRunes += n
+				if bold > 0 {
+					boldRunes += n
+				}
+			}
+
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "text":
+				inText = false
+				if line := strings.TrimSpace(text.String()); line != "" {
+					doc.lines = append(doc.lines, layoutLine{
+						page: page,
+						text: line,
+						size: fonts[font],
+						bold: boldRunes == totalRunes,
+					})
+				}
+
+			case "b":
+				bold = max(0, bold-1)
+
+			case "outline":
+				depth--
+
+			case "item":
+				inItem = false
+				if title := strings.Join(strings.Fields(text.String()), " "); title != "" {
+					doc.outline = append(doc.outline, heading{Level: max(1, depth), Title: title})
+				}
+			}
+		}
+	}
+
+	return doc, nil
+}
+
+func attr(e xml.StartElement, name string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+
+	return ""
+}
+
+// layoutHeadings returns the lines set in a font larger than the text of the
+// book, or in bold at its size, that look like a title. The larger the font,
+// the higher the level, unless the heading is numbered. Lines found on many
+// pages, like running headers, are left out, and a heading broken over two
+// lines of the same font is joined.
+func (doc pdfLayout) layoutHeadings() []heading {
+	if len(doc.lines) == 0 {
+		return nil
+	}
+
+	// The size of the text of the book is the one most characters use.
+	runes := make(map[float64]int)
+	pages := make(map[string]map[int]struct{})
+	for _, l := range doc.lines {
+		runes[l.size] += len([]rune(l.text))
+
+		if pages[l.text] == nil {
+			pages[l.text] = make(map[int]struct{})
+		}
+		pages[l.text][l.page] = struct{}{}
+	}
+
+	var body float64
+	for size, n := range runes {
+		if n > runes[body] {
+			body = size
+		}
+	}
+
+	type candidate struct {
+		layoutLine
+		index int
+	}
+
+	var candidates []candidate
+	for i, l := range doc.lines {
+		switch {
+		case len(pages[l.text]) > 2:
+		case l.size > body+1, l.size >= body && l.bold:
+			candidates = append(candidates, candidate{l, i})
+		}
+	}
+
+	// The heading sizes from the largest, the bold text of the body size
+	// being the smallest.
+	var sizes []float64
+	for _, c := range candidates {
+		if !slices.Contains(sizes, c.size) {
+			sizes = append(sizes, c.size)
+		}
+	}
+	slices.Sort(sizes)
+	slices.Reverse(sizes)
+
+	var headings []heading
+
+	// The candidate the last heading ends with, so only its own next line
+	// is joined to it.
+	last := -1
+
+	for i, c := range candidates {
+		// A heading continued on the next line of the same font.
+		if last >= 0 && last == i-1 {
+			prev := candidates[last]
+			if prev.index == c.index-1 && prev.page == c.page && prev.size == c.size {
+				h := &headings[len(headings)-1]
+				h.Title += " " + c.text
+				last = i
+				continue
+			}
+		}
+
+		if !looksLikeTitle(c.text) {
+			continue
+		}
+		last = i
+
+		level := slices.Index(sizes, c.size) + 1
+		if number := headingNumber(c.text); number != nil {
+			level = len(number)
+		}
+
+		headings = append(headings, heading{Level: level, Title: c.text})
+	}
+
+	// Numbers and page footers aside, a book has more than a couple of
+	// headings.
+	if len(headings) < 3 {
+		return nil
+	}
+
+	return headings
+}
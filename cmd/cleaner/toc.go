@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// heading represents a section heading of the book.
+type heading struct {
+	Level int
+	Title string
+
+	// Offset is the byte offset of the heading in the text of the book, or
+	// -1 when it wasn't found.
+	Offset int
+}
+
+// detectHeadings returns the headings of the book and where they were found.
+// The outline of the PDF is used when present. Otherwise the headings come
+// from the table of contents printed in the book, the layout of the pages,
+// like the size of the fonts, or the numbered headings of the text, in that
+// order.
+func detectHeadings(pdfFile string, text string) ([]heading, string, error) {
+	doc, err := readPDFLayout(pdfFile)
+	if err != nil {
+		fmt.Printf("Layout not available, using the text only: %s\n", err)
+	}
+
+	if headings := doc.outline; len(headings) > 0 {
+		return headings, "the outline of the PDF", nil
+	}
+
+	if headings := printedTOC(text); len(headings) > 0 {
+		return headings, "the table of contents printed in the book", nil
+	}
+
+	if headings := doc.layoutHeadings(); len(headings) > 0 {
+		return headings, "the font sizes of the pages", nil
+	}
+
+	if headings := numberedHeadings(text); len(headings) > 0 {
+		return headings, "the numbered headings of the text", nil
+	}
+
+	return nil, "", fmt.Errorf("no heading found in %s", pdfFile)
+}
+
+// =============================================================================
+
+var (
+	tocEntryPattern = regexp.MustCompile(`^(\S.*?)\s*\.{4,}\s*\d+$`)
+	chapterPattern  = regexp.MustCompile(`^(?i:chapter|part)\s+(\d+)\b`)
+	numberPattern   = regexp.MustCompile(`^(\d+(?:\.\d+)*)\.?\s+\S`)
+)
+
+// printedTOC returns the headings listed in the table of contents of the
+// book, the lines made of a title, dot leaders and a page number. The table
+// ends at the first stretch of lines without an entry longer than a page
+// break, which leaves out the tables with dot leaders in the chapters.
+//
+// The title of the table itself, like "Table of Contents", is a heading too,
+// after the front matter found before it, so the table doesn't end up in the
+// text of the section before.
+func printedTOC(text string) []heading {
+	var headings []heading
+	var first, last int
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if len(headings) > 0 && i-last > 20 {
+			break
+		}
+
+		m := tocEntryPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || strings.Contains(m[1], "....") {
+			continue
+		}
+
+		if len(headings) == 0 {
+			first = i
+		}
+		last = i
+
+		headings = append(headings, heading{
+			Level: headingLevel(m[1]),
+			Title: m[1],
+		})
+	}
+
+	// A few lines with dot leaders, like a table of latencies, are not a
+	// table of contents.
+	if len(headings) < 3 {
+		return nil
+	}
+
+	if first == 0 || !looksLikeTitle(strings.TrimSpace(lines[first-1])) {
+		return headings
+	}
+
+	before := make(map[string]bool)
+	for _, line := range lines[:first-1] {
+		before[titleKey(line)] = true
+	}
+
+	var n int
+	for n < len(headings) && before[titleKey(headings[n].Title)] {
+		n++
+	}
+
+	toc := heading{Level: 1, Title: strings.TrimSpace(lines[first-1])}
+
+	return slices.Insert(headings, n, toc)
+}
+
+// numberedHeadings returns the lines of the text following a blank line that
+// look like a chapter or a numbered section, like "Chapter 2: Language
+// Mechanics" or "2.4 Declare and Initialize". The numbers have to follow each
+// other, which leaves out the lines like "3 times faster".
+func numberedHeadings(text string) []heading {
+	var headings []heading
+	var last []int
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if i > 0 && strings.TrimSpace(lines[i-1]) != "" {
+			continue
+		}
+
+		if !looksLikeTitle(line) {
+			continue
+		}
+
+		number := headingNumber(line)
+		if number == nil || !follows(last, number) {
+			continue
+		}
+
+		headings = append(headings, heading{
+			Level: headingLevel(line),
+			Title: line,
+		})
+		last = number
+	}
+
+	return headings
+}
+
+// looksLikeTitle reports whether the line could be a heading: short, with
+// letters and not ending like a sentence.
+func looksLikeTitle(line string) bool {
+	if line == "" || len(strings.Fields(line)) > 12 {
+		return false
+	}
+
+	if strings.ContainsAny(line[len(line)-1:], ".,;:") {
+		return false
+	}
+
+	return strings.IndexFunc(line, func(r rune) bool {
+		return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z'
+	}) >= 0
+}
+
+// headingNumber returns the numbers of a chapter or numbered heading, like
+// [2] for "Chapter 2: Language Mechanics" and [2 4] for "2.4 Declare and
+// Initialize", or nil.
+func headingNumber(title string) []int {
+	if m := chapterPattern.FindStringSubmatch(title); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return []int{n}
+	}
+
+	m := numberPattern.FindStringSubmatch(title)
+	if m == nil {
+		return nil
+	}
+
+	var number []int
+	for _, part := range strings.Split(m[1], ".") {
+		n, _ := strconv.Atoi(part)
+		number = append(number, n)
+	}
+
+	return number
+}
+
+// headingLevel returns the level of a heading from its number, starting at
+// 1 for chapters and unnumbered headings.
+func headingLevel(title string) int {
+	return max(1, len(headingNumber(title)))
+}
+
+// follows reports whether the number can come right after the last one: the
+// first child of it, or the next one at one of its levels, whose children
+// start at 1.
+func follows(last []int, number []int) bool {
+	ones := func(parts []int) bool {
+		for _, n := range parts {
+			if n != 1 {
+				return false
+			}
+		}
+		return true
+	}
+
+	if last == nil {
+		return ones(number[1:]) && number[0] <= 1 || ones(number)
+	}
+
+	// The first child, like 1.8.1 after 1.8.
+	if len(number) > len(last) && equal(number[:len(last)], last) && ones(number[len(last):]) {
+		return true
+	}
+
+	// The next one at a level, like 1.9 or 2 after 1.8.3.
+	for k := 1; k <= min(len(last), len(number)); k++ {
+		if equal(number[:k-1], last[:k-1]) && number[k-1] == last[k-1]+1 && ones(number[k:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func equal(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// =============================================================================
+
+// locateHeadings sets the offset of every heading to the first line of the
+// text after the previous heading that holds its title, or to -1 when there
+// is none. The titles are compared ignoring the case, the spacing and the
+// section numbers, since the outline may not have them. A title broken over
+// two lines is found too.
+func locateHeadings(text string, headings []heading) {
+	type line struct {
+		offset int
+		key    string
+	}
+
+	var lines []line
+	var offset int
+	for _, l := range strings.SplitAfter(text, "\n") {
+		lines = append(lines, line{offset: offset, key: titleKey(l)})
+		offset += len(l)
+	}
+
+	var next int
+	for i := range headings {
+		headings[i].Offset = -1
+		key := titleKey(headings[i].Title)
+
+		for j := next; j < len(lines); j++ {
+			joined := lines[j].key
+			if j+1 < len(lines) {
+				joined = titleKey(joined + " " + lines[j+1].key)
+			}
+
+			if lines[j].key == key || joined == key {
+				headings[i].Offset = lines[j].offset
+				next = j + 1
+				break
+			}
+		}
+	}
+}
+
+var sectionNumberPattern = regexp.MustCompile(`^(?:(?:chapter|part)\s+\d+\s*[:.]?|\d+(?:\.\d+)*\.?)\s+`)
+
+// titleKey returns the title in lowercase, with single spaces and without its
+// section number.
+func titleKey(title string) string {
+	key := strings.ToLower(strings.Join(strings.Fields(title), " "))
+	return sectionNumberPattern.ReplaceAllString(key, "")
+}
+
+// =============================================================================
+
+// writeTOC writes the headings for review, one per line indented by level.
+// Headings not found in the text are commented out.
+func writeTOC(w io.Writer, headings []heading, source string) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# Table of contents detected from %s.\n", source)
+	fmt.Fprintln(bw, "# Review it before chunking: one heading per line, indented two spaces")
+	fmt.Fprintln(bw, "# per level. Lines starting with # are ignored.")
+
+	for _, h := range headings {
+		indent := strings.Repeat("  ", h.Level-1)
+
+		switch {
+		case h.Offset < 0:
+			fmt.Fprintf(bw, "# not found in the text: %s%s\n", indent, h.Title)
+		default:
+			fmt.Fprintf(bw, "%s%s\n", indent, h.Title)
+		}
+	}
+
+	return bw.Flush()
+}
+
+// readTOC reads the headings of a file written by writeTOC.
+func readTOC(fileName string) ([]heading, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer file.Close()
+
+	var headings []heading
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		title := strings.TrimSpace(line)
+		if title == "" || strings.HasPrefix(title, "#") {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		headings = append(headings, heading{
+			Level: indent/2 + 1,
+			Title: title,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	return headings, nil
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxBody is the max size of a request body.
+const maxBody = 16 << 20
+
+// api serves the embedding endpoints.
+type api struct {
+	registry  *registry
+	maxBatch  int
+	lowercase bool
+	started   time.Time
+}
+
+func (a *api) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v1/embeddings", a.openAIEmbeddings)
+	mux.HandleFunc("GET /v1/models", a.openAIModels)
+
+	mux.HandleFunc("POST /api/embed", a.ollamaEmbed)
+	mux.HandleFunc("POST /api/embeddings", a.ollamaEmbeddings)
+	mux.HandleFunc("GET /api/tags", a.ollamaTags)
+
+	mux.HandleFunc("GET /healthz", a.health)
+	mux.HandleFunc("GET /readyz", a.ready)
+
+	return mux
+}
+
+// =============================================================================
+
+// inputs represents the input of an embedding request, which is either a
+// single text or an array of texts.
+type inputs []string
+
+func (in *inputs) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*in = inputs{text}
+		return nil
+	}
+
+	var texts []string
+	if err := json.Unmarshal(data, &texts); err != nil {
+		return errors.New("input must be a string or an array of strings")
+	}
+
+	*in = texts
+	return nil
+}
+
+// embed calculates the embeddings of the texts with a single call into the
+// model. The embeddings are normalized to unit length like the ones of
+// OpenAI, so clients can compare them with a dot product. A text without any
+// known word gets a zero vector, since the APIs expect a vector for every
+// input. It also returns the number of words of the texts, reported as
+// tokens.
+func (a *api) embed(m *served, texts []string) ([][]float32, int) {
+	docs := make([]string, len(texts))
+	var words int
+
+	for i, text := range texts {
+		if a.lowercase {
+			text = strings.ToLower(text)
+		}
+		docs[i] = text
+		words += len(strings.Fields(text))
+	}
+
+	vectors, _ := m.model.EmbedBatch(docs)
+	for i, vec := range vectors {
+		if vec == nil {
+			vectors[i] = make([]float32, m.size)
+			continue
+		}
+
+		var norm float64
+		for _, v := range vec {
+			norm += float64(v) * float64(v)
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for k := range vec {
+				vec[k] = float32(float64(vec[k]) / norm)
+			}
+		}
+	}
+
+	return vectors, words
+}
+
+func (a *api) checkBatch(texts []string) error {
+	switch {
+	case len(texts) == 0:
+		return errors.New("input is empty")
+	case len(texts) > a.maxBatch:
+		return fmt.Errorf("input has %d texts, max is %d", len(texts), a.maxBatch)
+	}
+
+	for i, text := range texts {
+		if strings.IndexByte(text, 0) >= 0 {
+			return fmt.Errorf("input %d contains a NUL character", i)
+		}
+	}
+
+	return nil
+}
+
+// =============================================================================
+// OpenAI API: https://platform.openai.com/docs/api-reference/embeddings
+
+type openAIRequest struct {
+	Input          inputs `json:"input"`
+	Model          string `json:"model"`
+	EncodingFormat string `json:"encoding_format"`
+	Dimensions     int    `json:"dimensions"`
+}
+
+type openAIEmbedding struct {
+	Object    string `json:"object"`
+	Index     int    `json:"index"`
+	Embedding any    `json:"embedding"`
+}
+
+type openAIUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+type openAIResponse struct {
+	Object string            `json:"object"`
+	Data   []openAIEmbedding `json:"data"`
+	Model  string            `json:"model"`
+	Usage  openAIUsage       `json:"usage"`
+}
+
+func (a *api) openAIEmbeddings(w http.ResponseWriter, r *http.Request) {
+	var req openAIRequest
+	if err := decode(w, r, &req); err != nil {
+		openAIError(w, http.StatusBadRequest, "", err.Error())
+		return
+	}
+
+	m, exists := a.registry.lookup(req.Model)
+	if !exists {
+		openAIError(w, http.StatusNotFound, "model_not_found", fmt.Sprintf("model %q does not exist", req.Model))
+		return
+	}
+
+	if err := a.checkBatch(req.Input); err != nil {
+		openAIError(w, http.StatusBadRequest, "", err.Error())
+		return
+	}
+
+	if req.Dimensions != 0 && req.Dimensions != m.size {
+		openAIError(w, http.StatusBadRequest, "", fmt.Sprintf("model %q only supports %d dimensions", m.name, m.size))
+		return
+	}
+
+	if req.EncodingFormat != "" && req.EncodingFormat != "float" && req.EncodingFormat != "base64" {
+		openAIError(w, http.StatusBadRequest, "", fmt.Sprintf("unknown encoding format %q", req.EncodingFormat))
+		return
+	}
+
+	vectors, tokens := a.embed(m, req.Input)
+
+	resp := openAIResponse{
+		Object: "list",
+		Data:   make([]openAIEmbedding, len(vectors)),
+		Model:  m.name,
+		Usage:  openAIUsage{PromptTokens: tokens, TotalTokens: tokens},
+	}
+
+	for i, vec := range vectors {
+		var embedding any = vec
+		if req.EncodingFormat == "base64" {
+			embedding = encodeBase64(vec)
+		}
+		resp.Data[i] = openAIEmbedding{Object: "embedding", Index: i, Embedding: embedding}
+	}
+
+	respond(w, http.StatusOK, resp)
+}
+
+func (a *api) openAIModels(w http.ResponseWriter, r *http.Request) {
+	type model struct {
+		ID      string `json:"id"`
+		Object  string `json:"object"`
+		Created int64  `json:"created"`
+		OwnedBy string `json:"owned_by"`
+	}
+
+	resp := struct {
+		Object string  `json:"object"`
+		Data   []model `json:"data"`
+	}{
+		Object: "list",
+	}
+
+	for _, m := range a.registry.models {
+		resp.Data = append(resp.Data, model{ID: m.name, Object: "model", Created: a.started.Unix(), OwnedBy: "local"})
+	}
+
+	respond(w, http.StatusOK, resp)
+}
+
+// openAIError responds with an error the way OpenAI does. The type of the
+// errors the server returns is always invalid_request_error, and the code is
+// null unless the error has a specific one, like model_not_found.
+func openAIError(w http.ResponseWriter, status int, code string, msg string) {
+	type detail struct {
+		Message string  `json:"message"`
+		Type    string  `json:"type"`
+		Code    *string `json:"code"`
+	}
+
+	d := detail{Message: msg, Type: "invalid_request_error"}
+	if code != "" {
+		d.Code = &code
+	}
+
+	respond(w, status, struct {
+		Error detail `json:"error"`
+	}{
+		Error: d,
+	})
+}
+
+// encodeBase64 encodes the vector as the base64 of its little endian
+// float32 values, the way OpenAI clients ask for by default.
+func encodeBase64(vec []float32) string {
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// =============================================================================
+// Ollama API: https://github.com/ollama/ollama/blob/main/docs/api.md
+
+type ollamaEmbedRequest struct {
+	Model string `json:"model"`
+	Input inputs `json:"input"`
+}
+
+type ollamaEmbedResponse struct {
+	Model           string      `json:"model"`
+	Embeddings      [][]float32 `json:"embeddings"`
+	TotalDuration   int64       `json:"total_duration"`
+	LoadDuration    int64       `json:"load_duration"`
+	PromptEvalCount int         `json:"prompt_eval_count"`
+}
+
+func (a *api) ollamaEmbed(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	var req ollamaEmbedRequest
+	if err := decode(w, r, &req); err != nil {
+		ollamaError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	m, exists := a.registry.lookup(req.Model)
+	if !exists {
+		ollamaError(w, http.StatusNotFound, fmt.Sprintf("model %q not found", req.Model))
+		return
+	}
+
+	if err := a.checkBatch(req.Input); err != nil {
+		ollamaError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	vectors, tokens := a.embed(m, req.Input)
+
+	respond(w, http.StatusOK, ollamaEmbedResponse{
+		Model:           m.name,
+		Embeddings:      vectors,
+		TotalDuration:   time.Since(start).Nanoseconds(),
+		PromptEvalCount: tokens,
+	})
+}
+
+func (a *api) ollamaEmbeddings(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Model  string `json:"model"`
+		Prompt string `json:"prompt"`
+	}
+	if err := decode(w, r, &req); err != nil {
+		ollamaError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	m, exists := a.registry.lookup(req.Model)
+	if !exists {
+		ollamaError(w, http.StatusNotFound, fmt.Sprintf("model %q not found", req.Model))
+		return
+	}
+
+	if err := a.checkBatch([]string{req.Prompt}); err != nil {
+		ollamaError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	vectors, _ := a.embed(m, []string{req.Prompt})
+
+	respond(w, http.StatusOK, struct {
+		Embedding []float32 `json:"embedding"`
+	}{
+		Embedding: vectors[0],
+	})
+}
+
+func (a *api) ollamaTags(w http.ResponseWriter, r *http.Request) {
+	type details struct {
+		Format string `json:"format"`
+		Family string `json:"family"`
+	}
+
+	type model struct {
+		Name       string    `json:"name"`
+		Model      string    `json:"model"`
+		ModifiedAt time.Time `json:"modified_at"`
+		Size       int64     `json:"size"`
+		Details    details   `json:"details"`
+	}
+
+	var resp struct {
+		Models []model `json:"models"`
+	}
+
+	for _, m := range a.registry.models {
+		mdl := model{
+			Name:       m.name + ":latest",
+			Model:      m.name + ":latest",
+			ModifiedAt: a.started,
+			Details:    details{Format: "word2vec", Family: "word2vec"},
+		}
+
+		if info, err := os.Stat(m.file); err == nil {
+			mdl.ModifiedAt = info.ModTime()
+			mdl.Size = info.Size()
+		}
+
+		resp.Models = append(resp.Models, mdl)
+	}
+
+	respond(w, http.StatusOK, resp)
+}
+
+func ollamaError(w http.ResponseWriter, status int, msg string) {
+	respond(w, status, struct {
+		Error string `json:"error"`
+	}{
+		Error: msg,
+	})
+}
+
+// =============================================================================
+
+func (a *api) health(w http.ResponseWriter, r *http.Request) {
+	respond(w, http.StatusOK, struct {
+		Status string `json:"status"`
+	}{
+		Status: "ok",
+	})
+}
+
+func (a *api) ready(w http.ResponseWriter, r *http.Request) {
+	resp := struct {
+		Status string   `json:"status"`
+		Models []string `json:"models"`
+	}{
+		Status: "ok",
+	}
+
+	for _, m := range a.registry.models {
+		resp.Models = append(resp.Models, m.name)
+	}
+
+	respond(w, http.StatusOK, resp)
+}
+
+// =============================================================================
+
+func decode(w http.ResponseWriter, r *http.Request, v any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("decode request: %w", err)
+	}
+
+	return nil
+}
+
+func respond(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("respond: %v", err)
+	}
+}
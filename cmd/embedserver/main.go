@@ -0,0 +1,194 @@
+// This program serves our own word2vec models through the embedding APIs of
+// OpenAI and Ollama, so tools speaking either API, like the Open WebUI
+// container in zarf/docker/compose.yaml, can use them. The embedding of a
+// text with several words is the average of the vectors of its words, the
+// same way the document index of the word2vec package works.
+//
+// # Running the program:
+//
+//   $ make embedserver MODELS=reviews=zarf/data/example3.model
+//
+// Several models are loaded with a comma separated list of name=file pairs.
+// Requests pick a model by name and fall back to the first one.
+//
+// # Endpoints:
+//
+//   POST /v1/embeddings   OpenAI embeddings, float or base64 encoded.
+//   GET  /v1/models       OpenAI model list.
+//   POST /api/embed       Ollama embeddings.
+//   POST /api/embeddings  Ollama embeddings, legacy single prompt form.
+//   GET  /api/tags        Ollama model list.
+//   GET  /healthz         Liveness check.
+//   GET  /readyz          Readiness check, with the loaded models.
+//
+// # Open WebUI:
+//
+// Set the embedding engine of the documents settings to OpenAI with the URL
+// http://host.docker.internal:8090/v1, any API key and the model name.
+//
+// # WARNING
+//
+// This program uses the same C++ based dynamic library as example3, see the
+// notes of that example on how to build it.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/word2vec"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	addr := flag.String("addr", ":8090", "address to listen on")
+	models := flag.String("models", "reviews=zarf/data/example3.model", "comma separated name=file models to serve")
+	maxBatch := flag.Int("max-batch", 2048, "max number of inputs in a request")
+	lowercase := flag.Bool("lowercase", true, "lowercase the inputs, for models trained on lowercased text")
+	flag.Parse()
+
+	reg, err := loadModels(*models)
+	if err != nil {
+		return err
+	}
+	defer reg.close()
+
+	for _, m := range reg.models {
+		log.Printf("model %q: %s, %d words, vector size %d", m.name, m.file, m.model.Size(), m.size)
+	}
+
+	api := api{
+		registry:  reg,
+		maxBatch:  *maxBatch,
+		lowercase: *lowercase,
+		started:   time.Now(),
+	}
+
+	srv := http.Server{
+		Addr:              *addr,
+		Handler:           api.routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		log.Printf("listening on %s", *addr)
+		serverErrors <- srv.ListenAndServe()
+	}()
+
+	shutdown := make(chan os.Signal, 1)
+	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErrors:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server: %w", err)
+		}
+
+	case sig := <-shutdown:
+		log.Printf("shutdown: %v", sig)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(ctx); err != nil {
+			srv.Close()
+			return fmt.Errorf("could not stop server gracefully: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// served represents a model loaded for serving.
+type served struct {
+	name  string
+	file  string
+	size  int
+	model word2vec.Model
+}
+
+// registry holds the served models in the order they were listed. The first
+// one is used when a request does not name a model.
+type registry struct {
+	models []*served
+	byName map[string]*served
+}
+
+// loadModels loads the models of a comma separated list of name=file.
+func loadModels(list string) (*registry, error) {
+	reg := registry{
+		byName: make(map[string]*served),
+	}
+
+	for _, spec := range strings.Split(list, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		name, file, found := strings.Cut(spec, "=")
+		if !found || name == "" || file == "" {
+			reg.close()
+			return nil, fmt.Errorf("model %q: expected name=file", spec)
+		}
+
+		if _, exists := reg.byName[name]; exists {
+			reg.close()
+			return nil, fmt.Errorf("model %q: listed twice", name)
+		}
+
+		model, err := word2vec.Load(file, 0)
+		if err != nil {
+			reg.close()
+			return nil, fmt.Errorf("model %q: load %s: %w", name, file, err)
+		}
+
+		m := served{name: name, file: file, size: model.VectorSize(), model: model}
+		reg.models = append(reg.models, &m)
+		reg.byName[name] = &m
+	}
+
+	if len(reg.models) == 0 {
+		return nil, errors.New("no models provided")
+	}
+
+	return &reg, nil
+}
+
+// lookup returns the model of the name, ignoring the ":latest" tag Ollama
+// clients add, or the first model when the name is empty.
+func (r *registry) lookup(name string) (*served, bool) {
+	if name == "" {
+		return r.models[0], true
+	}
+
+	m, exists := r.byName[strings.TrimSuffix(name, ":latest")]
+	return m, exists
+}
+
+func (r *registry) close() {
+	for _, m := range r.models {
+		m.model.Close()
+	}
+}
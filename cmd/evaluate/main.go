@@ -0,0 +1,190 @@
+// This program scores a word2vec model against standard benchmarks, so the
+// models trained with different configurations can be compared objectively.
+// It reports the Spearman correlation on word similarity files, the accuracy
+// per section on analogy files and how much of every benchmark the model
+// covers. Models trained with the glove package can be scored as well since
+// they are written in the same format.
+//
+// # Running the program:
+//
+//   $ make evaluate MODEL=zarf/data/example3.model SIMILARITY=wordsim353.tsv ANALOGY=questions-words.txt
+//
+// # Benchmark files:
+//
+//   http://alfonseca.org/eng/research/wordsim353.html
+//   https://fh295.github.io/simlex.html
+//   https://github.com/tmikolov/word2vec/blob/master/questions-words.txt
+//
+// # WARNING
+//
+// This program uses the same C++ based dynamic library as example3, see the
+// notes of that example on how to build it.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/ardanlabs/ai-training/foundation/evaluate"
+	"github.com/ardanlabs/ai-training/foundation/word2vec"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	config := evaluate.NewConfigDefault()
+
+	fileModel := flag.String("model", "zarf/data/example3.model", "word2vec model file")
+	fileSubwords := flag.String("subwords", "", "subwords file to compose vectors of unknown words")
+	similarity := flag.String("similarity", "", "comma separated word similarity files")
+	analogy := flag.String("analogy", "", "comma separated analogy files")
+	flag.IntVar(&config.Restrict, "restrict", config.Restrict, "number of most frequent words searched for analogy answers, all when zero")
+	flag.BoolVar(&config.Lowercase, "lowercase", config.Lowercase, "lowercase the benchmark words")
+	flag.Parse()
+
+	if *similarity == "" && *analogy == "" {
+		return fmt.Errorf("no benchmark provided, use -similarity or -analogy")
+	}
+
+	// Hitting Ctrl-C stops the analogy search.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	model, err := word2vec.Load(*fileModel, 0)
+	if err != nil {
+		return fmt.Errorf("load model: %w", err)
+	}
+	defer model.Close()
+
+	config.Vector = model.VectorSize()
+
+	if *fileSubwords != "" {
+		if err := model.LoadSubwords(*fileSubwords); err != nil {
+			return fmt.Errorf("load subwords: %w", err)
+		}
+	}
+
+	fmt.Printf("Model: %s, %d words\n\n", *fileModel, model.Size())
+
+	for _, file := range splitFiles(*similarity) {
+		if err := evalSimilarity(&model, file, config); err != nil {
+			return fmt.Errorf("similarity %s: %w", file, err)
+		}
+	}
+
+	if *analogy == "" {
+		return nil
+	}
+
+	// The sentence delimiter is not a word, so it is never an answer.
+	var vocabulary []string
+	for word := range model.Words() {
+		if word != "</s>" {
+			vocabulary = append(vocabulary, word)
+		}
+	}
+
+	for _, file := range splitFiles(*analogy) {
+		if err := evalAnalogy(ctx, &model, vocabulary, file, config); err != nil {
+			return fmt.Errorf("analogy %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+func evalSimilarity(model *word2vec.Model, file string, config evaluate.Config) error {
+	pairs, err := evaluate.ReadPairs(file)
+	if err != nil {
+		return err
+	}
+
+	result, err := evaluate.Similarity(model, pairs, config)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Similarity:", file)
+	fmt.Printf("  Spearman: %s\n", score(result.Spearman))
+	fmt.Printf("  Coverage: %d/%d pairs (%.1f%%)\n", result.Evaluated, result.Pairs, 100*result.Coverage())
+	printOOV(result.OOV)
+	fmt.Print("\n")
+
+	return nil
+}
+
+func evalAnalogy(ctx context.Context, model *word2vec.Model, vocabulary []string, file string, config evaluate.Config) error {
+	sections, err := evaluate.ReadAnalogies(file)
+	if err != nil {
+		return err
+	}
+
+	result, err := evaluate.Analogy(ctx, model, vocabulary, sections, config)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Analogy:", file)
+	for _, sr := range result.Sections {
+		printSection(sr)
+	}
+	fmt.Print("  ---\n")
+	printSection(result.Semantic)
+	printSection(result.Syntactic)
+	printSection(result.Total)
+	printOOV(result.OOV)
+	fmt.Print("\n")
+
+	return nil
+}
+
+// =============================================================================
+
+func printSection(sr evaluate.SectionResult) {
+	fmt.Printf("  %-30s accuracy: %6.2f%% (%d/%d), coverage: %6.2f%% (%d/%d)\n",
+		sr.Name, 100*sr.Accuracy(), sr.Correct, sr.Evaluated,
+		100*sr.Coverage(), sr.Evaluated, sr.Questions)
+}
+
+func printOOV(oov []string) {
+	const show = 20
+
+	if len(oov) == 0 {
+		return
+	}
+
+	fmt.Printf("  OOV words: %d", len(oov))
+	if len(oov) > show {
+		fmt.Printf(", first %d: %s ...\n", show, strings.Join(oov[:show], " "))
+		return
+	}
+	fmt.Printf(": %s\n", strings.Join(oov, " "))
+}
+
+func score(v float64) string {
+	if math.IsNaN(v) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.4f", v)
+}
+
+func splitFiles(files string) []string {
+	var list []string
+	for _, file := range strings.Split(files, ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			list = append(list, file)
+		}
+	}
+	return list
+}
@@ -1,6 +1,7 @@
 // This example shows you how to train the word2vec model with your own content
 // and leverage the model's nearest neighbor support. It also shows you how to
-// use the cosine similarity algorithm to test similarity.
+// use the cosine similarity algorithm to test similarity and how to index the
+// reviews to find the ones most similar to a query.
 //
 // # Running the example:
 //
@@ -32,12 +33,16 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
 	"runtime"
 
+	"github.com/ardanlabs/ai-training/foundation/clean"
 	"github.com/ardanlabs/ai-training/foundation/stopwords"
 	"github.com/ardanlabs/ai-training/foundation/vector"
 	"github.com/ardanlabs/ai-training/foundation/word2vec"
@@ -50,75 +55,79 @@ func main() {
 }
 
 func run() error {
-	if err := cleanData(); err != nil {
-		return fmt.Errorf("cleanData: %w", err)
+	// Hitting Ctrl-C cancels the context and stops the training cleanly.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	// The cleaned reviews are streamed straight into the training, so
+	// they are never written to disk.
+	pr, pw := io.Pipe()
+	errs := make(chan error, 1)
+
+	var stats clean.Stats
+	go func() {
+		var err error
+		stats, err = cleanData(ctx, pw)
+		pw.CloseWithError(err)
+		errs <- err
+	}()
+
+	if err := trainModel(ctx, pr); err != nil {
+		return fmt.Errorf("trainModel: %w", err)
 	}
 
-	if err := trainModel(); err != nil {
-		return fmt.Errorf("trainModel: %w", err)
+	if err := <-errs; err != nil {
+		return fmt.Errorf("cleanData: %w", err)
 	}
 
+	fmt.Println("Cleaning Data Stats ...")
+	fmt.Print("\n")
+	fmt.Println(stats)
+
 	if err := testModel(); err != nil {
 		return fmt.Errorf("trainModel: %w", err)
 	}
 
+	if err := testDocIndex(); err != nil {
+		return fmt.Errorf("testDocIndex: %w", err)
+	}
+
 	return nil
 }
 
-func cleanData() error {
-	type document struct {
-		ReviewText string
-	}
-
+func cleanData(ctx context.Context, w io.Writer) (clean.Stats, error) {
 	input, err := os.Open("zarf/data/example3.json")
 	if err != nil {
-		return fmt.Errorf("open file: %w", err)
+		return clean.Stats{}, fmt.Errorf("open file: %w", err)
 	}
 	defer input.Close()
 
-	output, err := os.Create("zarf/data/example3.words")
+	filter, err := stopwords.NewFilter("en")
 	if err != nil {
-		return fmt.Errorf("create file: %w", err)
+		return clean.Stats{}, fmt.Errorf("stop words: %w", err)
 	}
-	defer output.Close()
-
-	var counter int
-
-	fmt.Print("\033[s")
-
-	scanner := bufio.NewScanner(input)
-	for scanner.Scan() {
-		s := scanner.Text()
-
-		var d document
-		err := json.Unmarshal([]byte(s), &d)
-		if err != nil {
-			return fmt.Errorf("unmarshal: %w", err)
-		}
-
-		v := stopwords.Remove(d.ReviewText)
-
-		output.WriteString(v)
-		output.WriteString("\n")
 
-		counter++
+	pipeline := clean.New(
+		clean.NewConfigDefault(),
+		clean.JSONField("reviewText"),
+		clean.RemoveStopWords(filter),
+	)
 
-		fmt.Print("\033[u\033[K")
-		fmt.Printf("Reading/Cleaning Data: %d", counter)
+	stats, err := pipeline.Run(ctx, input, w)
+	if err != nil {
+		return stats, fmt.Errorf("run: %w", err)
 	}
 
-	fmt.Print("\n")
-
-	return nil
+	return stats, nil
 }
 
-func trainModel() error {
+func trainModel(ctx context.Context, sentences io.Reader) error {
 	fmt.Println("Training Model ...")
 	fmt.Print("\n")
 
 	config := word2vec.Config{
 		Corpus: word2vec.ConfigCorpus{
-			InputFile: "zarf/data/example3.words",
+			Input:     sentences,
 			Tokenizer: " \n,.-!?:;/\"#$%&'()*+<=>@[]\\^_`{|}~\t\v\f\r",
 			Sequencer: ".\n?!",
 		},
@@ -128,6 +137,11 @@ func trainModel() error {
 			Threshold: 1e-3,
 			Frequency: 5,
 		},
+		Subword: word2vec.ConfigSubword{
+			MinN:    3,
+			MaxN:    6,
+			Buckets: 200000,
+		},
 		Learning: word2vec.ConfigLearning{
 			Epoch: 10,
 			Rate:  0.05,
@@ -140,9 +154,11 @@ func trainModel() error {
 		Threads:                runtime.GOMAXPROCS(0),
 		Verbose:                true,
 		Output:                 "zarf/data/example3.model",
+		OutputVocabulary:       "zarf/data/example3.vocab",
+		OutputSubwords:         "zarf/data/example3.subwords",
 	}
 
-	if err := word2vec.Train(config); err != nil {
+	if err := word2vec.Train(ctx, config); err != nil {
 		return fmt.Errorf("train: %w", err)
 	}
 
@@ -187,5 +203,83 @@ func testModel() error {
 		fmt.Printf("The cosine similarity between the word %q and %q: %.3f%%\n", words[i], words[i+1], v*100)
 	}
 
+	// -------------------------------------------------------------------------
+
+	// With the subwords loaded, misspelled words get a vector composed from
+	// the character n-grams they share with the words in the vocabulary.
+	if err := w2v.LoadSubwords("zarf/data/example3.subwords"); err != nil {
+		return err
+	}
+
+	w2v.Lookup("batery", seq)
+
+	fmt.Print("\n")
+	fmt.Println("Top 10 words similar to the misspelled \"batery\"")
+	fmt.Println(seq)
+
+	return nil
+}
+
+func testDocIndex() error {
+	fmt.Print("\n")
+	fmt.Println("Testing Document Index ...")
+	fmt.Print("\n")
+
+	w2v, err := word2vec.Load("zarf/data/example3.model", 300)
+	if err != nil {
+		return err
+	}
+	defer w2v.Close()
+
+	type document struct {
+		ReviewText string
+	}
+
+	input, err := os.Open("zarf/data/example3.json")
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer input.Close()
+
+	idx := word2vec.NewDocIndex(&w2v)
+	defer idx.Close()
+
+	// Index the first 10k reviews using the line number as the document id.
+	var reviews []string
+
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() && len(reviews) < 10_000 {
+		var d document
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return fmt.Errorf("unmarshal: %w", err)
+		}
+
+		id := uint64(len(reviews))
+		reviews = append(reviews, d.ReviewText)
+
+		// Reviews without any known word can't be indexed.
+		idx.Add(id, stopwords.Remove(d.ReviewText))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	if err := idx.Save("zarf/data/example3.docs"); err != nil {
+		return err
+	}
+
+	const query = "battery died after a week"
+
+	docs, err := idx.Lookup(stopwords.Remove(query), 3)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Top 3 reviews similar to %q\n", query)
+	for _, doc := range docs {
+		fmt.Printf("%.3f%%: %s\n", doc.Distance*100, reviews[doc.ID])
+	}
+
 	return nil
 }
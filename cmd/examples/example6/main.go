@@ -37,6 +37,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/ai-training/foundation/mongodb"
+	"github.com/ardanlabs/ai-training/foundation/pii"
 	"github.com/tmc/langchaingo/llms/ollama"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -102,6 +103,24 @@ func createEmbeddings() error {
 	}
 	defer output.Close()
 
+	// Personal data is redacted before it reaches ollama and mongodb, and
+	// the redactions are logged without the values that were removed. The
+	// names are left alone, since the authors quoted in the book are not
+	// personal data and terms like "Mark Assist" would be redacted too.
+	redactions, err := os.Create("zarf/data/book.redactions")
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer redactions.Close()
+
+	redactor, err := pii.New(pii.Config{
+		Kinds: []pii.Kind{pii.Email, pii.Phone, pii.CreditCard, pii.SSN},
+		Log:   redactions,
+	})
+	if err != nil {
+		return fmt.Errorf("pii: %w", err)
+	}
+
 	fmt.Print("\n")
 	fmt.Print("\033[s")
 
@@ -121,6 +140,11 @@ func createEmbeddings() error {
 		chunk := strings.Trim(chunk, "<CHUNK>")
 		chunk = strings.Trim(chunk, "</CHUNK>")
 
+		chunk, _, err := redactor.Redact(chunk)
+		if err != nil {
+			return fmt.Errorf("redact: %w", err)
+		}
+
 		// Get the vector embedding for this chunk.
 		embedding, err := llm.CreateEmbedding(context.Background(), []string{chunk})
 		if err != nil {
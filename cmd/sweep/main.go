@@ -0,0 +1,262 @@
+// This program searches the word2vec settings that work best for a corpus.
+// Every combination of the settings, or a random sample of them, is trained
+// on the corpus and scored against word similarity and analogy benchmarks.
+// The runs are ranked on a leaderboard written as CSV or JSON, depending on
+// the extension of the output file.
+//
+// # Running the program:
+//
+//   $ make sweep CORPUS=zarf/data/corpus.txt SIMILARITY=wordsim353.tsv
+//
+// Settings take comma separated values, for example:
+//
+//   -model cbow,skip-gram -approximation ns,hs -window 5,10 -vector 100,300
+//
+// # WARNING
+//
+// This program uses the same C++ based dynamic library as example3, see the
+// notes of that example on how to build it.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/evaluate"
+	"github.com/ardanlabs/ai-training/foundation/sweep"
+	"github.com/ardanlabs/ai-training/foundation/word2vec"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	base := word2vec.NewConfigDefault()
+	base.Verbose = false
+
+	corpus := flag.String("corpus", "", "train data file")
+	stopWords := flag.String("stopwords", "", "stop words file")
+	similarity := flag.String("similarity", "", "comma separated word similarity files")
+	analogy := flag.String("analogy", "", "comma separated analogy files")
+	output := flag.String("out", "zarf/data/leaderboard.csv", "leaderboard file, .csv or .json")
+	dir := flag.String("dir", "", "directory to keep the trained models in")
+	random := flag.Int("random", 0, "number of random candidates, the whole grid when zero")
+	restrict := flag.Int("restrict", 30000, "number of most frequent words searched for analogy answers")
+
+	models := flag.String("model", "skip-gram", "learning models: cbow, skip-gram")
+	approximations := flag.String("approximation", "ns", "softmax approximations: ns, hs")
+	vectors := flag.String("vector", "100", "vector sizes")
+	windows := flag.String("window", "5,10", "window sizes")
+	thresholds := flag.String("threshold", "1e-3", "down-sampling thresholds")
+	frequencies := flag.String("frequency", "5", "min word frequencies")
+	negatives := flag.String("negative", "5", "numbers of negative examples")
+	epochs := flag.String("epoch", "5", "numbers of training runs")
+	rates := flag.String("rate", "0.05", "starting learning rates")
+	flag.Parse()
+
+	if *corpus == "" {
+		return fmt.Errorf("no corpus provided, use -corpus")
+	}
+
+	base.Corpus.InputFile = *corpus
+	base.Corpus.StopWordsFile = *stopWords
+
+	evaluator, err := newEvaluator(*similarity, *analogy, *restrict)
+	if err != nil {
+		return err
+	}
+
+	params, err := newParams(map[string]string{
+		"model":         *models,
+		"approximation": *approximations,
+		"vector":        *vectors,
+		"window":        *windows,
+		"threshold":     *thresholds,
+		"frequency":     *frequencies,
+		"negative":      *negatives,
+		"epoch":         *epochs,
+		"rate":          *rates,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Settings with a single value are the same for every run, so they are
+	// applied to the base config and kept out of the candidates.
+	var varying []sweep.Param
+	for _, p := range params {
+		if len(p.Values) == 1 {
+			p.Values[0].Apply(&base)
+			continue
+		}
+		varying = append(varying, p)
+	}
+
+	candidates := sweep.Grid(varying...)
+	if *random > 0 {
+		candidates = sweep.Random(*random, varying...)
+	}
+
+	// Hitting Ctrl-C stops the sweep, the finished runs are still written.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	config := sweep.Config{
+		Base:       base,
+		Candidates: candidates,
+		Evaluator:  evaluator,
+		Dir:        *dir,
+		OnResult: func(r sweep.Result) {
+			if r.Err != nil {
+				fmt.Printf("run %d/%d: %s: %v\n", r.Run, len(candidates), r.Candidate, r.Err)
+				return
+			}
+			fmt.Printf("run %d/%d: %s: score %.4f in %v\n", r.Run, len(candidates), r.Candidate, r.Score, r.Duration.Round(time.Millisecond))
+		},
+	}
+
+	fmt.Printf("Sweeping %d candidates\n\n", len(candidates))
+
+	lb, sweepErr := sweep.Run(ctx, config)
+
+	if err := writeLeaderboard(lb, *output); err != nil {
+		return err
+	}
+
+	if best, ok := lb.Best(); ok {
+		fmt.Printf("\nBest: %s: score %.4f\n", best.Candidate, best.Score)
+	}
+	fmt.Println("Leaderboard:", *output)
+
+	return sweepErr
+}
+
+func newEvaluator(similarity string, analogy string, restrict int) (sweep.Evaluator, error) {
+	config := evaluate.NewConfigDefault()
+	config.Restrict = restrict
+
+	var evaluators []sweep.Evaluator
+
+	for _, file := range splitList(similarity) {
+		pairs, err := evaluate.ReadPairs(file)
+		if err != nil {
+			return nil, fmt.Errorf("similarity %s: %w", file, err)
+		}
+		evaluators = append(evaluators, sweep.Similarity(pairs, config))
+	}
+
+	for _, file := range splitList(analogy) {
+		sections, err := evaluate.ReadAnalogies(file)
+		if err != nil {
+			return nil, fmt.Errorf("analogy %s: %w", file, err)
+		}
+		evaluators = append(evaluators, sweep.Analogy(sections, config))
+	}
+
+	if len(evaluators) == 0 {
+		return nil, fmt.Errorf("no benchmark provided, use -similarity or -analogy")
+	}
+
+	return sweep.Mean(evaluators...), nil
+}
+
+func newParams(flags map[string]string) ([]sweep.Param, error) {
+	ints := map[string]func(...int) sweep.Param{
+		"vector":    sweep.Vector,
+		"window":    sweep.Window,
+		"frequency": sweep.Frequency,
+		"negative":  sweep.Negative,
+		"epoch":     sweep.Epoch,
+	}
+
+	floats := map[string]func(...float64) sweep.Param{
+		"threshold": sweep.Threshold,
+		"rate":      sweep.Rate,
+	}
+
+	// The order of the params is the order of the leaderboard columns.
+	names := []string{"model", "approximation", "vector", "window", "threshold", "frequency", "negative", "epoch", "rate"}
+
+	var params []sweep.Param
+	for _, name := range names {
+		values := splitList(flags[name])
+
+		var p sweep.Param
+		var err error
+
+		switch name {
+		case "model":
+			p, err = sweep.Model(values...)
+
+		case "approximation":
+			p, err = sweep.Approximation(values...)
+
+		case "threshold", "rate":
+			var fs []float64
+			for _, v := range values {
+				f, perr := strconv.ParseFloat(v, 64)
+				if perr != nil || math.IsNaN(f) {
+					return nil, fmt.Errorf("%s: invalid value %q", name, v)
+				}
+				fs = append(fs, f)
+			}
+			p = floats[name](fs...)
+
+		default:
+			var is []int
+			for _, v := range values {
+				i, perr := strconv.Atoi(v)
+				if perr != nil {
+					return nil, fmt.Errorf("%s: invalid value %q", name, v)
+				}
+				is = append(is, i)
+			}
+			p = ints[name](is...)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		params = append(params, p)
+	}
+
+	return params, nil
+}
+
+func writeLeaderboard(lb sweep.Leaderboard, output string) error {
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(output), ".json") {
+		return lb.WriteJSON(f)
+	}
+
+	return lb.WriteCSV(f)
+}
+
+func splitList(list string) []string {
+	var values []string
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,163 @@
+// This program audits the associations a model of word embeddings learned
+// from its training data with Word Embedding Association Tests. The target
+// and attribute word sets of every test are read from a JSON file, and the
+// effect size and permutation test p-value of every test are reported. It
+// works with word2vec models and with the embeddings produced by Ollama, so
+// the product review model can be compared with a general purpose one.
+//
+// # Running the program:
+//
+//   $ make weat MODEL=zarf/data/example3.model
+//   $ make weat-ollama
+//
+// The default tests in zarf/data/weat.json replicate the career and family
+// test of the original paper, plus a test on product review vocabulary.
+//
+// # WARNING
+//
+// This program uses the same C++ based dynamic library as example3, see the
+// notes of that example on how to build it.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ardanlabs/ai-training/foundation/weat"
+	"github.com/ardanlabs/ai-training/foundation/word2vec"
+	"github.com/tmc/langchaingo/llms/ollama"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	config := weat.NewConfigDefault()
+
+	fileTests := flag.String("tests", "zarf/data/weat.json", "JSON file of the tests")
+	fileModel := flag.String("model", "zarf/data/example3.model", "word2vec model file")
+	ollamaModel := flag.String("ollama", "", "Ollama embedding model to test instead of the word2vec model")
+	flag.IntVar(&config.Permutations, "permutations", config.Permutations, "max number of partitions of the p-value")
+	flag.Parse()
+
+	tests, err := weat.ReadTests(*fileTests)
+	if err != nil {
+		return fmt.Errorf("read tests: %w", err)
+	}
+
+	var model weat.Model
+
+	switch *ollamaModel {
+	case "":
+		w2v, err := word2vec.Load(*fileModel, 0)
+		if err != nil {
+			return fmt.Errorf("load model: %w", err)
+		}
+		defer w2v.Close()
+
+		model = &w2v
+		config.Vector = w2v.VectorSize()
+		fmt.Printf("Model: %s\n\n", *fileModel)
+
+	default:
+		emb, err := ollamaEmbeddings(*ollamaModel, tests)
+		if err != nil {
+			return err
+		}
+
+		model = emb
+		config.Vector = emb.size
+		fmt.Printf("Model: ollama %s\n\n", *ollamaModel)
+	}
+
+	for _, test := range tests {
+		result, err := weat.Run(model, test, config)
+		if err != nil {
+			fmt.Printf("%s: %v\n\n", test.Name, err)
+			continue
+		}
+
+		exact := "sampled"
+		if result.Exact {
+			exact = "exact"
+		}
+
+		fmt.Println(test.Name)
+		fmt.Printf("  %s vs %s with %s vs %s\n", test.TargetX.Name, test.TargetY.Name, test.AttributeA.Name, test.AttributeB.Name)
+		fmt.Printf("  words: %d/%d/%d/%d\n", result.X, result.Y, result.A, result.B)
+		fmt.Printf("  effect size: %.4f\n", result.EffectSize)
+		fmt.Printf("  p-value: %.4f (%s, %d partitions)\n", result.PValue, exact, result.Permutations)
+		if len(result.OOV) > 0 {
+			fmt.Printf("  OOV words: %s\n", strings.Join(result.OOV, " "))
+		}
+		fmt.Print("\n")
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// embeddings holds the Ollama embeddings of the test words, so every word is
+// embedded once for all the tests.
+type embeddings struct {
+	size    int
+	vectors map[string][]float32
+}
+
+func ollamaEmbeddings(model string, tests []weat.Test) (*embeddings, error) {
+	llm, err := ollama.New(ollama.WithModel(model))
+	if err != nil {
+		return nil, fmt.Errorf("ollama: %w", err)
+	}
+
+	seen := make(map[string]struct{})
+	var words []string
+	for _, test := range tests {
+		for _, word := range test.Words() {
+			if _, exists := seen[word]; !exists {
+				seen[word] = struct{}{}
+				words = append(words, word)
+			}
+		}
+	}
+
+	vectors, err := llm.CreateEmbedding(context.Background(), words)
+	if err != nil {
+		return nil, fmt.Errorf("create embedding: %w", err)
+	}
+
+	if len(vectors) != len(words) || len(vectors) == 0 {
+		return nil, fmt.Errorf("create embedding: got %d embeddings for %d words", len(vectors), len(words))
+	}
+
+	emb := embeddings{
+		size:    len(vectors[0]),
+		vectors: make(map[string][]float32, len(words)),
+	}
+	for i, word := range words {
+		emb.vectors[word] = vectors[i]
+	}
+
+	return &emb, nil
+}
+
+// VectorOf copies the embedding of the word into vector.
+func (e *embeddings) VectorOf(word string, vector []float32) error {
+	vec, exists := e.vectors[word]
+	if !exists || len(vec) != e.size {
+		return errors.New("unknown tokens")
+	}
+
+	copy(vector, vec)
+
+	return nil
+}
@@ -0,0 +1,237 @@
+// Package align provides support for comparing word embeddings trained
+// separately, such as two versions of a model or a model and a pretrained
+// one. Every training ends with vectors rotated arbitrarily, so the vector of
+// a word in one model can not be compared with its vector in the other.
+//
+// Align learns the orthogonal mapping that best rotates the vectors of shared
+// anchor words from one space onto the other, which is the orthogonal
+// Procrustes problem, and applies it to the whole space. Once aligned, the
+// words whose vectors still differ the most are the ones whose meaning
+// shifted between the two models:
+// https://arxiv.org/abs/1605.09096
+package align
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ardanlabs/ai-training/foundation/vector"
+)
+
+// Model represents a source of word vectors. It is satisfied by the word2vec
+// and glove models.
+type Model interface {
+	VectorOf(word string, vector []float32) error
+}
+
+// Space represents word vectors normalized to unit length.
+type Space struct {
+	size    int
+	words   []string
+	index   map[string]int
+	vectors []float32
+}
+
+// NewSpace reads the vectors of the words from the model. Words the model has
+// no vector for are skipped, as well as words repeated in the list.
+func NewSpace(m Model, words []string, size int) (*Space, error) {
+	if size <= 0 {
+		return nil, errors.New("vector size must be greater than zero")
+	}
+
+	s := Space{
+		size:  size,
+		index: make(map[string]int, len(words)),
+	}
+
+	vec := make([]float32, size)
+	for _, word := range words {
+		if _, exists := s.index[word]; exists {
+			continue
+		}
+
+		if err := m.VectorOf(word, vec); err != nil {
+			continue
+		}
+
+		if !vector.Normalize(vec) {
+			continue
+		}
+
+		s.index[word] = len(s.words)
+		s.words = append(s.words, word)
+		s.vectors = append(s.vectors, vec...)
+	}
+
+	if len(s.words) == 0 {
+		return nil, errors.New("no vectors found for the words")
+	}
+
+	return &s, nil
+}
+
+// Size returns the number of words in the space.
+func (s *Space) Size() int {
+	return len(s.words)
+}
+
+// VectorSize returns the number of data points of the vectors.
+func (s *Space) VectorSize() int {
+	return s.size
+}
+
+// Words returns the words of the space in the order they were read.
+func (s *Space) Words() []string {
+	return s.words
+}
+
+// Contains reports whether the word is part of the space.
+func (s *Space) Contains(word string) bool {
+	_, exists := s.index[word]
+	return exists
+}
+
+// VectorOf copies the vector of the word into vector.
+func (s *Space) VectorOf(word string, vector []float32) error {
+	i, exists := s.index[word]
+	if !exists {
+		return errors.New("unknown tokens")
+	}
+
+	copy(vector, s.vector(i))
+
+	return nil
+}
+
+// Shared returns the words of the space that are part of the other space too
+// in the order of this space.
+func (s *Space) Shared(other *Space) []string {
+	var words []string
+	for _, word := range s.words {
+		if other.Contains(word) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func (s *Space) vector(i int) []float32 {
+	return s.vectors[i*s.size : (i+1)*s.size]
+}
+
+// =============================================================================
+
+// Mapping represents an orthogonal matrix rotating the vectors of a space
+// onto another space. Being orthogonal, it keeps the lengths of the vectors
+// and the angles between them, so the similarities within the rotated space
+// do not change.
+type Mapping struct {
+	size int
+	w    []float64
+}
+
+// Learn finds the orthogonal mapping that brings the vectors of the anchor
+// words in src closest to their vectors in dst. Anchors missing from either
+// space are ignored. There should be well more anchors than the vector size,
+// since they are meant to be the words whose meaning did not change.
+func Learn(src *Space, dst *Space, anchors []string) (*Mapping, error) {
+	if src.size != dst.size {
+		return nil, fmt.Errorf("vector size %d, expected %d", src.size, dst.size)
+	}
+
+	size := src.size
+
+	// The mapping is U*V' where U*S*V' is the singular value decomposition
+	// of X'*Y, the rows of X and Y being the anchor vectors in src and dst.
+	m := make([]float64, size*size)
+
+	var found int
+	for _, word := range anchors {
+		i, exists := src.index[word]
+		if !exists {
+			continue
+		}
+		j, exists := dst.index[word]
+		if !exists {
+			continue
+		}
+		found++
+
+		x, y := src.vector(i), dst.vector(j)
+		for r := 0; r < size; r++ {
+			xr := float64(x[r])
+			row := m[r*size : (r+1)*size]
+			for c := range row {
+				row[c] += xr * float64(y[c])
+			}
+		}
+	}
+
+	if found < size {
+		return nil, fmt.Errorf("not enough anchor words: %d, need at least %d", found, size)
+	}
+
+	u, v := svd(m, size)
+
+	mp := Mapping{
+		size: size,
+		w:    make([]float64, size*size),
+	}
+
+	for r := 0; r < size; r++ {
+		for c := 0; c < size; c++ {
+			var sum float64
+			for k := 0; k < size; k++ {
+				sum += u[r*size+k] * v[c*size+k]
+			}
+			mp.w[r*size+c] = sum
+		}
+	}
+
+	return &mp, nil
+}
+
+// Map rotates the vector into the destination space.
+func (mp *Mapping) Map(vec []float32) ([]float32, error) {
+	if len(vec) != mp.size {
+		return nil, fmt.Errorf("vector size %d, expected %d", len(vec), mp.size)
+	}
+
+	out := make([]float32, mp.size)
+	mp.mapTo(out, vec)
+
+	return out, nil
+}
+
+// Apply returns a copy of the space with every vector rotated into the
+// destination space.
+func (mp *Mapping) Apply(s *Space) (*Space, error) {
+	if s.size != mp.size {
+		return nil, fmt.Errorf("vector size %d, expected %d", s.size, mp.size)
+	}
+
+	aligned := Space{
+		size:    s.size,
+		words:   s.words,
+		index:   s.index,
+		vectors: make([]float32, len(s.vectors)),
+	}
+
+	for i := range s.words {
+		vec := aligned.vector(i)
+		mp.mapTo(vec, s.vector(i))
+		vector.Normalize(vec)
+	}
+
+	return &aligned, nil
+}
+
+func (mp *Mapping) mapTo(out []float32, vec []float32) {
+	for c := 0; c < mp.size; c++ {
+		var sum float64
+		for r := 0; r < mp.size; r++ {
+			sum += float64(vec[r]) * mp.w[r*mp.size+c]
+		}
+		out[c] = float32(sum)
+	}
+}
@@ -0,0 +1,130 @@
+package align
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/ardanlabs/ai-training/foundation/vector"
+)
+
+// Shift represents how much the meaning of a word changed between two
+// aligned spaces, as the cosine similarity of its two vectors.
+type Shift struct {
+	Word       string
+	Similarity float32
+}
+
+// Shifts compares the vectors of the words in the aligned space with their
+// vectors in dst and returns them from the most shifted word to the least
+// shifted one. Words missing from either space are ignored. When words is
+// nil, all the words shared by both spaces are compared.
+func Shifts(aligned *Space, dst *Space, words []string) []Shift {
+	if words == nil {
+		words = aligned.Shared(dst)
+	}
+
+	shifts := make([]Shift, 0, len(words))
+	for _, word := range words {
+		i, exists := aligned.index[word]
+		if !exists {
+			continue
+		}
+		j, exists := dst.index[word]
+		if !exists {
+			continue
+		}
+
+		shifts = append(shifts, Shift{
+			Word:       word,
+			Similarity: float32(vector.Dot(aligned.vector(i), dst.vector(j))),
+		})
+	}
+
+	sort.SliceStable(shifts, func(i, j int) bool {
+		return shifts[i].Similarity < shifts[j].Similarity
+	})
+
+	return shifts
+}
+
+// =============================================================================
+
+// Neighbour represents a word and its cosine similarity to a vector.
+type Neighbour struct {
+	Word       string
+	Similarity float32
+}
+
+// Nearest returns up to k words of the space nearest to the vector in
+// descending order of similarity, leaving out the excluded words. It is
+// handy to show how the neighbourhood of a shifted word changed.
+func (s *Space) Nearest(vec []float32, k int, exclude ...string) []Neighbour {
+	if len(vec) != s.size || k <= 0 {
+		return nil
+	}
+
+	skip := make(map[string]struct{}, len(exclude))
+	for _, word := range exclude {
+		skip[word] = struct{}{}
+	}
+
+	var nearest []Neighbour
+	for i, word := range s.words {
+		if _, exists := skip[word]; exists {
+			continue
+		}
+
+		n := Neighbour{Word: word, Similarity: float32(vector.Dot(vec, s.vector(i)))}
+
+		if len(nearest) == k && n.Similarity <= nearest[k-1].Similarity {
+			continue
+		}
+
+		pos := sort.Search(len(nearest), func(j int) bool {
+			return nearest[j].Similarity < n.Similarity
+		})
+
+		if len(nearest) < k {
+			nearest = append(nearest, Neighbour{})
+		}
+		copy(nearest[pos+1:], nearest[pos:])
+		nearest[pos] = n
+	}
+
+	return nearest
+}
+
+// Save writes the space in the binary format of the word2vec tool, so an
+// aligned space can be loaded back with word2vec.Load or glove.Load.
+func (s *Space) Save(fileModel string) error {
+	f, err := os.Create(fileModel)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "%d %d\n", len(s.words), s.size)
+
+	buf := make([]byte, 4*s.size)
+	for i, word := range s.words {
+		for k, v := range s.vector(i) {
+			binary.LittleEndian.PutUint32(buf[4*k:], math.Float32bits(v))
+		}
+
+		w.WriteString(word)
+		w.WriteByte(' ')
+		w.Write(buf)
+		w.WriteByte('\n')
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
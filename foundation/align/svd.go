@@ -0,0 +1,81 @@
+package align
+
+import "math"
+
+// svd decomposes the n x n row major matrix a into U*S*V' with the one-sided
+// Jacobi method and returns U and V. Columns of U are left zero for the zero
+// singular values. The matrix a is overwritten.
+func svd(a []float64, n int) (u []float64, v []float64) {
+	const (
+		eps       = 1e-12
+		maxSweeps = 60
+	)
+
+	v = make([]float64, n*n)
+	for i := 0; i < n; i++ {
+		v[i*n+i] = 1
+	}
+
+	// Every sweep rotates pairs of columns of a, and the same way the columns
+	// of v, until all the columns of a are orthogonal. The columns of a are
+	// then the columns of U scaled by the singular values.
+	for sweep := 0; sweep < maxSweeps; sweep++ {
+		rotated := false
+
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				var alpha, beta, gamma float64
+				for i := 0; i < n; i++ {
+					ap, aq := a[i*n+p], a[i*n+q]
+					alpha += ap * ap
+					beta += aq * aq
+					gamma += ap * aq
+				}
+
+				if gamma == 0 || math.Abs(gamma) <= eps*math.Sqrt(alpha*beta) {
+					continue
+				}
+				rotated = true
+
+				zeta := (beta - alpha) / (2 * gamma)
+				t := math.Copysign(1, zeta) / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				c := 1 / math.Sqrt(1+t*t)
+				s := c * t
+
+				rotate(a, n, p, q, c, s)
+				rotate(v, n, p, q, c, s)
+			}
+		}
+
+		if !rotated {
+			break
+		}
+	}
+
+	u = make([]float64, n*n)
+	for j := 0; j < n; j++ {
+		var norm float64
+		for i := 0; i < n; i++ {
+			norm += a[i*n+j] * a[i*n+j]
+		}
+
+		if norm == 0 {
+			continue
+		}
+
+		norm = math.Sqrt(norm)
+		for i := 0; i < n; i++ {
+			u[i*n+j] = a[i*n+j] / norm
+		}
+	}
+
+	return u, v
+}
+
+func rotate(m []float64, n int, p int, q int, c float64, s float64) {
+	for i := 0; i < n; i++ {
+		mp, mq := m[i*n+p], m[i*n+q]
+		m[i*n+p] = c*mp - s*mq
+		m[i*n+q] = s*mp + c*mq
+	}
+}
@@ -0,0 +1,160 @@
+// Package chunk provides support for splitting documents into chunks to be
+// embedded and indexed, like the sections of a book.
+//
+// Several strategies are provided: fixed windows of words, characters or
+// tokens with an overlap, sentences packed up to a limit, and the recursive
+// splitting of the text at headings, then paragraphs, sentences and words,
+// until every chunk is under a limit. Every chunk carries its offsets in the
+// source text, so a search result can point back to its place in the
+// document.
+package chunk
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ardanlabs/ai-training/foundation/tokenizer"
+)
+
+// Chunk represents a piece of a document.
+type Chunk struct {
+	// Index is the position of the chunk in the document, starting at 0.
+	Index int
+
+	// Text is the text of the chunk, which is the source text
+	// text[Start:End].
+	Text string
+
+	// Start and End are the byte offsets of the chunk in the source text.
+	Start int
+	End   int
+
+	// RuneStart and RuneEnd are the rune offsets of the chunk in the source
+	// text.
+	RuneStart int
+	RuneEnd   int
+}
+
+// Splitter represents a strategy to split a text into chunks.
+type Splitter interface {
+	Split(text string) []Chunk
+}
+
+// SplitterFunc is an adapter to use a function as a Splitter.
+type SplitterFunc func(text string) []Chunk
+
+// Split implements the Splitter interface.
+func (f SplitterFunc) Split(text string) []Chunk {
+	return f(text)
+}
+
+// =============================================================================
+
+// Measure represents a function returning the size of a text, in the unit
+// the limit of a splitter is defined in.
+type Measure func(text string) int
+
+// WordCount measures a text by its number of words separated by spaces.
+func WordCount(text string) int {
+	return len(strings.Fields(text))
+}
+
+// RuneCount measures a text by its number of characters.
+func RuneCount(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// TokenCount returns a measure of a text by its number of tokens of the
+// tokenizer. It approximates the tokens of a model, which also splits words
+// into pieces, so the limit needs some headroom below the model's context.
+func TokenCount(t *tokenizer.Tokenizer) Measure {
+	return func(text string) int {
+		return len(t.Tokenize(text))
+	}
+}
+
+// =============================================================================
+
+// span represents the byte offsets of a piece of a text.
+type span struct {
+	start int
+	end   int
+}
+
+// trim returns the span without its surrounding spaces.
+func trim(text string, s span) span {
+	piece := text[s.start:s.end]
+
+	left := strings.TrimLeftFunc(piece, unicode.IsSpace)
+	s.start += len(piece) - len(left)
+	s.end = s.start + len(strings.TrimRightFunc(left, unicode.IsSpace))
+
+	return s
+}
+
+// chunks returns the chunks of the spans, skipping the blank ones and
+// counting the rune offsets along the way. The spans are in order and may
+// overlap.
+func chunks(text string, spans []span) []Chunk {
+	var result []Chunk
+	var pos, runes int
+
+	// runeOffset returns the rune offset of a byte offset, counting from the
+	// last one asked, which is never after it by more than the overlap.
+	runeOffset := func(offset int) int {
+		switch {
+		case offset >= pos:
+			runes += utf8.RuneCountInString(text[pos:offset])
+		default:
+			runes -= utf8.RuneCountInString(text[offset:pos])
+		}
+		pos = offset
+
+		return runes
+	}
+
+	for _, s := range spans {
+		s = trim(text, s)
+		if s.start == s.end {
+			continue
+		}
+
+		result = append(result, Chunk{
+			Index:     len(result),
+			Text:      text[s.start:s.end],
+			Start:     s.start,
+			End:       s.end,
+			RuneStart: runeOffset(s.start),
+			RuneEnd:   runeOffset(s.end),
+		})
+	}
+
+	return result
+}
+
+// words returns the spans of the words of text[s.start:s.end], separated by
+// spaces.
+func words(text string, s span) []span {
+	var spans []span
+
+	start := -1
+	for i, r := range text[s.start:s.end] {
+		switch {
+		case unicode.IsSpace(r):
+			if start >= 0 {
+				spans = append(spans, span{s.start + start, s.start + i})
+				start = -1
+			}
+
+		case start < 0:
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		spans = append(spans, span{s.start + start, s.end})
+	}
+
+	return spans
+}
@@ -0,0 +1,178 @@
+package chunk
+
+import (
+	"regexp"
+
+	"github.com/ardanlabs/ai-training/foundation/tokenizer"
+)
+
+// Level represents a boundary the recursive splitter splits a text at.
+type Level int
+
+// Set of boundaries, from the coarsest to the finest.
+const (
+	// ByHeading splits before the lines that look like a heading, like
+	// "# Title", "Chapter 3" or "2.4 Interfaces".
+	ByHeading Level = iota
+
+	// ByParagraph splits at blank lines.
+	ByParagraph
+
+	// ByLine splits at line breaks.
+	ByLine
+
+	// BySentence splits at the sentence boundaries of the tokenizer package.
+	BySentence
+
+	// ByWord splits at spaces.
+	ByWord
+)
+
+// Recursive returns a splitter into chunks of at most limit, as measured by
+// the measure. The text is split at the first level, like headings, and the
+// pieces under the limit are packed together up to the limit. Pieces over
+// the limit are split again at the next level, down to words. A single word
+// over the limit is a chunk of its own.
+//
+// Without levels, the text is split at headings, paragraphs, sentences and
+// words.
+func Recursive(limit int, measure Measure, levels ...Level) Splitter {
+	if len(levels) == 0 {
+		levels = []Level{ByHeading, ByParagraph, BySentence, ByWord}
+	}
+
+	return SplitterFunc(func(text string) []Chunk {
+		r := recursive{
+			text:    text,
+			limit:   limit,
+			measure: measure,
+			levels:  levels,
+		}
+
+		return chunks(text, r.split(span{0, len(text)}, 0))
+	})
+}
+
+// Sentences returns a splitter packing whole sentences into chunks of at
+// most limit, as measured by the measure. A sentence over the limit is split
+// at its words.
+func Sentences(limit int, measure Measure) Splitter {
+	return Recursive(limit, measure, BySentence, ByWord)
+}
+
+// =============================================================================
+
+type recursive struct {
+	text    string
+	limit   int
+	measure Measure
+	levels  []Level
+}
+
+// split returns the spans of the chunks of the span, split from the level.
+func (r recursive) split(s span, level int) []span {
+	if r.size(s) <= r.limit {
+		return []span{s}
+	}
+
+	if level == len(r.levels) {
+		return []span{s}
+	}
+
+	var result []span
+	var current span
+	var open bool
+
+	flush := func() {
+		if open {
+			result = append(result, current)
+			open = false
+		}
+	}
+
+	for _, piece := range r.pieces(s, r.levels[level]) {
+		if r.size(piece) > r.limit {
+			flush()
+			result = append(result, r.split(piece, level+1)...)
+			continue
+		}
+
+		switch {
+		case !open:
+			current, open = piece, true
+
+		case r.size(span{current.start, piece.end}) <= r.limit:
+			current.end = piece.end
+
+		default:
+			flush()
+			current, open = piece, true
+		}
+	}
+	flush()
+
+	return result
+}
+
+func (r recursive) size(s span) int {
+	return r.measure(r.text[s.start:s.end])
+}
+
+var (
+	headingPattern   = regexp.MustCompile(`(?m)^[ \t]*(?:#{1,6}[ \t]+\S|(?:Chapter|CHAPTER|Part|PART|Section|SECTION)[ \t]+\d+|\d+(?:\.\d+)*\.?[ \t]+\p{Lu})`)
+	paragraphPattern = regexp.MustCompile(`\n[ \t]*\n`)
+	linePattern      = regexp.MustCompile(`\n`)
+)
+
+// pieces returns the spans of the span split at the boundaries of the level.
+func (r recursive) pieces(s span, level Level) []span {
+	text := r.text[s.start:s.end]
+
+	var cuts [][]int
+
+	switch level {
+	case ByHeading:
+		// Headings start a piece, so the cut is empty right before them.
+		for _, m := range headingPattern.FindAllStringIndex(text, -1) {
+			cuts = append(cuts, []int{m[0], m[0]})
+		}
+
+	case ByParagraph:
+		cuts = paragraphPattern.FindAllStringIndex(text, -1)
+
+	case ByLine:
+		cuts = linePattern.FindAllStringIndex(text, -1)
+
+	case BySentence:
+		var spans []span
+		for _, st := range tokenizer.Sentences(text) {
+			spans = append(spans, span{s.start + st.Start, s.start + st.End})
+		}
+		return spans
+
+	default:
+		return words(r.text, s)
+	}
+
+	var spans []span
+	var pos int
+	for _, cut := range cuts {
+		if cut[0] > pos {
+			spans = append(spans, trim(r.text, span{s.start + pos, s.start + cut[0]}))
+		}
+		pos = cut[1]
+	}
+	if pos < len(text) {
+		spans = append(spans, trim(r.text, span{s.start + pos, s.end}))
+	}
+
+	// Blank pieces, like the spaces before a heading, are dropped.
+	result := spans[:0]
+	for _, sp := range spans {
+		if sp.start < sp.end {
+			result = append(result, sp)
+		}
+	}
+
+	return result
+}
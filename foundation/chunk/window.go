@@ -0,0 +1,95 @@
+package chunk
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ardanlabs/ai-training/foundation/tokenizer"
+)
+
+// Words returns a splitter into windows of size words, separated by spaces,
+// where every window repeats the last overlap words of the previous one. An
+// overlap not less than the size is replaced by half the size.
+func Words(size int, overlap int) Splitter {
+	return SplitterFunc(func(text string) []Chunk {
+		units := words(text, span{0, len(text)})
+		return chunks(text, windows(units, size, overlap))
+	})
+}
+
+// Chars returns a splitter into windows of size characters, where every
+// window repeats the last overlap characters of the previous one. The
+// windows don't look for word boundaries. An overlap not less than the size
+// is replaced by half the size.
+func Chars(size int, overlap int) Splitter {
+	return SplitterFunc(func(text string) []Chunk {
+		units := make([]span, 0, utf8.RuneCountInString(text))
+		for i, r := range text {
+			units = append(units, span{i, i + utf8.RuneLen(r)})
+		}
+
+		return chunks(text, windows(units, size, overlap))
+	})
+}
+
+// Tokens returns a splitter into windows of size tokens of the tokenizer,
+// where every window repeats the last overlap tokens of the previous one.
+// The punctuation right after the last token of a window, like the period of
+// a sentence, is kept with it. An overlap not less than the size is replaced
+// by half the size.
+func Tokens(size int, overlap int, t *tokenizer.Tokenizer) Splitter {
+	return SplitterFunc(func(text string) []Chunk {
+		tokens := t.Tokenize(text)
+
+		units := make([]span, len(tokens))
+		for i, tkn := range tokens {
+			units[i] = span{tkn.Start, tkn.End}
+		}
+
+		spans := windows(units, size, overlap)
+		for i := range spans {
+			spans[i].end = wordEnd(text, spans[i].end)
+		}
+
+		return chunks(text, spans)
+	})
+}
+
+// =============================================================================
+
+// windows returns the spans of the windows of size units, overlapping by
+// overlap units. An overlap of size-1 would already give a window for every
+// unit, so an overlap not less than the size is taken for a mistake and
+// replaced by half the size.
+func windows(units []span, size int, overlap int) []span {
+	size = max(size, 1)
+	overlap = max(overlap, 0)
+	if overlap >= size {
+		overlap = size / 2
+	}
+
+	var spans []span
+	for i := 0; i < len(units); i += size - overlap {
+		j := min(i+size, len(units))
+		spans = append(spans, span{units[i].start, units[j-1].end})
+
+		if j == len(units) {
+			break
+		}
+	}
+
+	return spans
+}
+
+// wordEnd returns the offset of the first space at or after the offset.
+func wordEnd(text string, offset int) int {
+	for offset < len(text) {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		if unicode.IsSpace(r) {
+			break
+		}
+		offset += size
+	}
+
+	return offset
+}
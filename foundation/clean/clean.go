@@ -0,0 +1,379 @@
+// Package clean provides support for cleaning text with a pipeline of stages,
+// like extracting a field of JSON records, stripping HTML, masking numbers
+// and removing stop words, before training a model or indexing documents.
+//
+// The pipeline reads one record per line and writes one cleaned record per
+// line, in the same order. Records are cleaned concurrently and the time and
+// effect of every stage are reported when the pipeline is done.
+package clean
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// Stage represents a step of the pipeline. A stage returning an empty text
+// drops the record.
+type Stage struct {
+	name string
+	fn   func(text string) (string, error)
+}
+
+// NewStage constructs a stage from a function, for steps the package does not
+// provide.
+func NewStage(name string, fn func(text string) (string, error)) Stage {
+	return Stage{
+		name: name,
+		fn:   fn,
+	}
+}
+
+// Name returns the name of the stage.
+func (s Stage) Name() string {
+	return s.name
+}
+
+// =============================================================================
+
+// Config defines the settings of the pipeline.
+type Config struct {
+	// Workers represents the number of records cleaned at the same time.
+	// Ex: runtime.GOMAXPROCS(0)
+	Workers int
+
+	// Buffer represents the max number of records read ahead of the one
+	// being written, which bounds the memory used to keep the order.
+	// Ex: 4096
+	Buffer int
+
+	// MaxLine represents the max size of a record in bytes.
+	// Ex: 16 << 20
+	MaxLine int
+
+	// SkipErrors represents dropping the records a stage fails on, like
+	// malformed JSON lines, instead of stopping the pipeline.
+	// Ex: false
+	SkipErrors bool
+}
+
+// NewConfigDefault defines a set of default configuration options.
+func NewConfigDefault() Config {
+	return Config{
+		Workers:    runtime.GOMAXPROCS(0),
+		Buffer:     4096,
+		MaxLine:    16 << 20,
+		SkipErrors: false,
+	}
+}
+
+// Pipeline represents a sequence of stages every record goes through. It is
+// safe for concurrent use.
+type Pipeline struct {
+	config Config
+	stages []Stage
+}
+
+// New constructs a pipeline of the stages, applied in order.
+func New(config Config, stages ...Stage) *Pipeline {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.Buffer < config.Workers {
+		config.Buffer = config.Workers
+	}
+	if config.MaxLine <= 0 {
+		config.MaxLine = bufio.MaxScanTokenSize
+	}
+
+	return &Pipeline{
+		config: config,
+		stages: stages,
+	}
+}
+
+// Apply cleans a single text, like a query that has to match the cleaned
+// records. It returns an empty text if a stage dropped it.
+func (p *Pipeline) Apply(text string) (string, error) {
+	text, _, err := p.apply(text, nil)
+	return text, err
+}
+
+// Run cleans every line read from r and writes the cleaned records to w, one
+// per line and in the order they were read. Line breaks left in a record by
+// the stages are replaced by spaces.
+func (p *Pipeline) Run(ctx context.Context, r io.Reader, w io.Writer) (Stats, error) {
+	start := time.Now()
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	type record struct {
+		seq  int
+		text string
+		keep bool
+		err  error
+	}
+
+	counters := make([]counter, len(p.stages))
+	jobs := make(chan record, p.config.Workers)
+	results := make(chan record, p.config.Workers)
+	inflight := make(chan struct{}, p.config.Buffer)
+
+	// The reader hands out the lines in sequence, never more than Buffer
+	// ahead of the writer.
+	var readErr error
+	go func() {
+		defer close(jobs)
+
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), p.config.MaxLine)
+
+		for seq := 0; scanner.Scan(); seq++ {
+			select {
+			case inflight <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case jobs <- record{seq: seq, text: scanner.Text()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		readErr = scanner.Err()
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(p.config.Workers)
+	for range p.config.Workers {
+		go func() {
+			defer wg.Done()
+			for rec := range jobs {
+				rec.text, rec.keep, rec.err = p.apply(rec.text, counters)
+				results <- rec
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// The records are written in sequence, keeping the ones cleaned ahead
+	// of their turn until the records before them are written.
+	var stats Stats
+	var err error
+
+	bw := bufio.NewWriter(w)
+	pending := make(map[int]record)
+	next := 0
+
+	for rec := range results {
+		pending[rec.seq] = rec
+
+		for {
+			rec, exists := pending[next]
+			if !exists {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-inflight
+
+			stats.Records++
+
+			switch {
+			case err != nil:
+
+			case rec.err != nil:
+				stats.Errors++
+				if !p.config.SkipErrors {
+					err = fmt.Errorf("line %d: %w", rec.seq+1, rec.err)
+					cancel(err)
+				}
+
+			case !rec.keep:
+				stats.Dropped++
+
+			default:
+				bw.WriteString(rec.text)
+				if werr := bw.WriteByte('\n'); werr != nil {
+					err = fmt.Errorf("write: %w", werr)
+					cancel(err)
+					break
+				}
+				stats.Written++
+			}
+		}
+	}
+
+	if err == nil {
+		if ferr := bw.Flush(); ferr != nil {
+			err = fmt.Errorf("write: %w", ferr)
+		}
+	}
+
+	switch {
+	case err != nil:
+	case readErr != nil:
+		err = fmt.Errorf("read: %w", readErr)
+	case ctx.Err() != nil:
+		err = context.Cause(ctx)
+	}
+
+	stats.Duration = time.Since(start)
+	stats.Stages = make([]StageStats, len(p.stages))
+	for i, s := range p.stages {
+		stats.Stages[i] = counters[i].stats(s.name)
+	}
+
+	return stats, err
+}
+
+var lineBreaks = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+// apply runs the stages over the text, updating the counters when provided.
+// It reports whether the record is kept.
+func (p *Pipeline) apply(text string, counters []counter) (string, bool, error) {
+	for i, s := range p.stages {
+		start := time.Now()
+		out, err := s.fn(text)
+
+		if counters != nil {
+			counters[i].update(text, out, err, time.Since(start))
+		}
+
+		switch {
+		case err != nil:
+			return "", false, fmt.Errorf("%s: %w", s.name, err)
+
+		case strings.TrimSpace(out) == "":
+			return "", false, nil
+		}
+
+		text = out
+	}
+
+	return lineBreaks.Replace(text), true, nil
+}
+
+// =============================================================================
+
+// Stats represents the outcome of a run of the pipeline.
+type Stats struct {
+	// Records is the number of records read.
+	Records int
+
+	// Written is the number of records written.
+	Written int
+
+	// Dropped is the number of records a stage left empty.
+	Dropped int
+
+	// Errors is the number of records a stage failed on.
+	Errors int
+
+	// Duration is the time the run took.
+	Duration time.Duration
+
+	// Stages holds the stats of every stage in order.
+	Stages []StageStats
+}
+
+// StageStats represents the work done by a stage.
+type StageStats struct {
+	Name string
+
+	// Records is the number of records the stage processed.
+	Records int
+
+	// Changed is the number of records the stage modified.
+	Changed int
+
+	// Dropped is the number of records the stage left empty.
+	Dropped int
+
+	// Errors is the number of records the stage failed on.
+	Errors int
+
+	// BytesIn and BytesOut are the sizes of the records before and after
+	// the stage.
+	BytesIn  int64
+	BytesOut int64
+
+	// Duration is the time spent in the stage, summed over the workers.
+	Duration time.Duration
+}
+
+// String implements the fmt.Stringer interface, with a table of the stages.
+func (s Stats) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Records: %d, written: %d, dropped: %d, errors: %d, time: %v\n\n",
+		s.Records, s.Written, s.Dropped, s.Errors, s.Duration.Round(time.Millisecond))
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "stage\trecords\tchanged\tdropped\terrors\tbytes in\tbytes out\ttime\t")
+	for _, st := range s.Stages {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%v\t\n",
+			st.Name, st.Records, st.Changed, st.Dropped, st.Errors, st.BytesIn, st.BytesOut, st.Duration.Round(time.Millisecond))
+	}
+	tw.Flush()
+
+	return b.String()
+}
+
+// counter collects the stats of a stage from several workers.
+type counter struct {
+	records  atomic.Int64
+	changed  atomic.Int64
+	dropped  atomic.Int64
+	errors   atomic.Int64
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
+	duration atomic.Int64
+}
+
+func (c *counter) update(in string, out string, err error, d time.Duration) {
+	c.records.Add(1)
+	c.bytesIn.Add(int64(len(in)))
+	c.duration.Add(int64(d))
+
+	switch {
+	case err != nil:
+		c.errors.Add(1)
+		return
+
+	case strings.TrimSpace(out) == "":
+		c.dropped.Add(1)
+	}
+
+	c.bytesOut.Add(int64(len(out)))
+	if out != in {
+		c.changed.Add(1)
+	}
+}
+
+func (c *counter) stats(name string) StageStats {
+	return StageStats{
+		Name:     name,
+		Records:  int(c.records.Load()),
+		Changed:  int(c.changed.Load()),
+		Dropped:  int(c.dropped.Load()),
+		Errors:   int(c.errors.Load()),
+		BytesIn:  c.bytesIn.Load(),
+		BytesOut: c.bytesOut.Load(),
+		Duration: time.Duration(c.duration.Load()),
+	}
+}
@@ -0,0 +1,269 @@
+package clean
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/ardanlabs/ai-training/foundation/langdetect"
+	"github.com/ardanlabs/ai-training/foundation/pii"
+	"github.com/ardanlabs/ai-training/foundation/stem"
+	"github.com/ardanlabs/ai-training/foundation/stopwords"
+	"github.com/ardanlabs/ai-training/foundation/tokenizer"
+	"golang.org/x/net/html"
+	"golang.org/x/text/unicode/norm"
+)
+
+// JSONField extracts the string value of a field from records holding a JSON
+// object, like the reviewText of the Amazon reviews. Nested fields are
+// separated by dots, like "review.text". Records without the field, or with
+// a null value, are dropped.
+func JSONField(path string) Stage {
+	fields := strings.Split(path, ".")
+
+	fn := func(text string) (string, error) {
+		raw := json.RawMessage(text)
+
+		for _, field := range fields {
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				return "", fmt.Errorf("unmarshal: %w", err)
+			}
+
+			var exists bool
+			if raw, exists = obj[field]; !exists {
+				return "", nil
+			}
+		}
+
+		var value *string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return "", fmt.Errorf("field %q is not a string", path)
+		}
+
+		if value == nil {
+			return "", nil
+		}
+
+		return *value, nil
+	}
+
+	return NewStage("json:"+path, fn)
+}
+
+// StripHTML removes the markup of HTML text, with the content of its script
+// and style elements, and decodes its entities. Elements breaking the flow of
+// the text, like paragraphs and line breaks, become spaces.
+func StripHTML() Stage {
+	fn := func(text string) (string, error) {
+		if !strings.ContainsAny(text, "<&") {
+			return text, nil
+		}
+
+		var b strings.Builder
+		var skip int
+
+		z := html.NewTokenizer(strings.NewReader(text))
+		for {
+			switch z.Next() {
+			case html.ErrorToken:
+				if err := z.Err(); !errors.Is(err, io.EOF) {
+					return "", err
+				}
+				return strings.Join(strings.Fields(b.String()), " "), nil
+
+			case html.TextToken:
+				if skip == 0 {
+					b.Write(z.Text())
+				}
+
+			case html.StartTagToken:
+				name, _ := z.TagName()
+				switch string(name) {
+				case "script", "style":
+					skip++
+				}
+				b.WriteByte(' ')
+
+			case html.EndTagToken:
+				name, _ := z.TagName()
+				switch string(name) {
+				case "script", "style":
+					if skip > 0 {
+						skip--
+					}
+				}
+				b.WriteByte(' ')
+
+			case html.SelfClosingTagToken:
+				b.WriteByte(' ')
+			}
+		}
+	}
+
+	return NewStage("html", fn)
+}
+
+// NFC normalizes the text to the Unicode canonical composition, so the same
+// accented letter written as one or two code points matches.
+func NFC() Stage {
+	return NewStage("nfc", func(text string) (string, error) {
+		return norm.NFC.String(text), nil
+	})
+}
+
+// NFKC normalizes the text to the Unicode compatibility composition, which
+// also folds variants like ligatures, full width letters and superscripts.
+func NFKC() Stage {
+	return NewStage("nfkc", func(text string) (string, error) {
+		return norm.NFKC.String(text), nil
+	})
+}
+
+// FoldAccents removes the accents of the letters, so "café" becomes "cafe".
+// Letters without a decomposition, like "ø" or "ß", are kept.
+func FoldAccents() Stage {
+	fn := func(text string) (string, error) {
+		decomposed := norm.NFD.String(text)
+
+		var b strings.Builder
+		b.Grow(len(decomposed))
+
+		for _, r := range decomposed {
+			if !unicode.Is(unicode.Mn, r) {
+				b.WriteRune(r)
+			}
+		}
+
+		return norm.NFC.String(b.String()), nil
+	}
+
+	return NewStage("fold", fn)
+}
+
+// Lowercase lowercases the text.
+func Lowercase() Stage {
+	return NewStage("lower", func(text string) (string, error) {
+		return strings.ToLower(text), nil
+	})
+}
+
+// MaskNumbers replaces the numbers of the text, like "42" or "1,234.56", by
+// the mask. Identifiers mixing letters and digits, like "v1.2", are kept.
+func MaskNumbers(mask string) Stage {
+	return maskStage("mask-numbers", mask, tokenizer.Number)
+}
+
+// MaskURLs replaces the URLs of the text by the mask.
+func MaskURLs(mask string) Stage {
+	return maskStage("mask-urls", mask, tokenizer.URL)
+}
+
+// MaskEmails replaces the email addresses of the text by the mask.
+func MaskEmails(mask string) Stage {
+	return maskStage("mask-emails", mask, tokenizer.Email)
+}
+
+// Tokenize replaces the text by its tokens separated by a space, which drops
+// the punctuation.
+func Tokenize(t *tokenizer.Tokenizer) Stage {
+	return NewStage("tokenize", func(text string) (string, error) {
+		return strings.Join(t.Words(text), " "), nil
+	})
+}
+
+// RemoveStopWords removes the stop words of the filter. It also lowercases
+// the text and drops the punctuation, as stopwords.Remove does.
+func RemoveStopWords(f *stopwords.Filter) Stage {
+	return NewStage("stopwords", func(text string) (string, error) {
+		return strings.TrimSpace(f.Remove(text)), nil
+	})
+}
+
+// Stem reduces every word of the text, separated by spaces, with a stemmer
+// or lemmatizer of the stem package.
+func Stem(fn stem.Func) Stage {
+	return NewStage("stem", func(text string) (string, error) {
+		return strings.Join(stem.Words(fn, strings.Fields(text)), " "), nil
+	})
+}
+
+// Redact replaces the personal data found by the redactor, like emails and
+// phone numbers, with placeholders or reversible tokens. It has to run before
+// the stages that lowercase the text or drop its punctuation, which the
+// detection relies on. The reversible tokens survive those stages, but not
+// stemming.
+func Redact(r *pii.Redactor) Stage {
+	return NewStage("redact", func(text string) (string, error) {
+		text, _, err := r.Redact(text)
+		return text, err
+	})
+}
+
+// Multilingual removes the stop words of the language detected for every
+// record, and reduces its words with the stemmer of that language when stemming
+// is set and the stem package has one. Records in an undetermined language, or
+// in a language without a stop word list, are left alone.
+func Multilingual(d *langdetect.Detector, stemming bool) Stage {
+	filters := make(map[string]*stopwords.Filter)
+
+	for _, lang := range d.Languages() {
+		f, err := stopwords.NewFilter(lang)
+		if err != nil {
+			continue
+		}
+
+		if stemming {
+			if fn, err := stem.ForLanguage(lang); err == nil {
+				f.Stem(fn)
+			}
+		}
+
+		filters[lang] = f
+	}
+
+	fn := func(text string) (string, error) {
+		f, exists := filters[d.Detect(text).Lang]
+		if !exists {
+			return text, nil
+		}
+
+		return strings.TrimSpace(f.Remove(text)), nil
+	}
+
+	return NewStage("multilingual", fn)
+}
+
+// =============================================================================
+
+// masker keeps the casing and apostrophes of the text, only the spans of the
+// tokens matter.
+var masker = tokenizer.New(tokenizer.Config{})
+
+func maskStage(name string, mask string, kind tokenizer.Kind) Stage {
+	fn := func(text string) (string, error) {
+		var b strings.Builder
+		var pos int
+
+		for _, tkn := range masker.Tokenize(text) {
+			if tkn.Kind != kind {
+				continue
+			}
+			b.WriteString(text[pos:tkn.Start])
+			b.WriteString(mask)
+			pos = tkn.End
+		}
+
+		if pos == 0 {
+			return text, nil
+		}
+		b.WriteString(text[pos:])
+
+		return b.String(), nil
+	}
+
+	return NewStage(name, fn)
+}
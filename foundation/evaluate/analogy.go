@@ -0,0 +1,327 @@
+package evaluate
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ardanlabs/ai-training/foundation/vector"
+)
+
+// Question represents the analogy "A is to B as C is to D", where D is the
+// answer expected from the model.
+type Question struct {
+	A string
+	B string
+	C string
+	D string
+}
+
+// Section represents a named group of analogy questions.
+type Section struct {
+	Name      string
+	Questions []Question
+}
+
+// ReadAnalogies reads an analogy benchmark in the questions-words.txt format.
+// A line starting with ':' opens a section named after the rest of the line
+// and every other line holds the four words of a question. Questions found
+// before the first section are put in a section without a name.
+func ReadAnalogies(fileAnalogies string) ([]Section, error) {
+	f, err := os.Open(fileAnalogies)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	var sections []Section
+	var questions int
+
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if name, found := strings.CutPrefix(line, ":"); found {
+			sections = append(sections, Section{Name: strings.TrimSpace(name)})
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("line %d: expected 4 words, got %d", lineNum, len(fields))
+		}
+
+		if len(sections) == 0 {
+			sections = append(sections, Section{})
+		}
+
+		s := &sections[len(sections)-1]
+		s.Questions = append(s.Questions, Question{A: fields[0], B: fields[1], C: fields[2], D: fields[3]})
+		questions++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	if questions == 0 {
+		return nil, errors.New("no analogy questions found")
+	}
+
+	return sections, nil
+}
+
+// =============================================================================
+
+// SectionResult represents the score of a model on a group of analogies.
+type SectionResult struct {
+	// Name is the name of the section.
+	Name string
+
+	// Questions is the number of questions in the section.
+	Questions int
+
+	// Evaluated is the number of questions whose four words are among the
+	// searched words.
+	Evaluated int
+
+	// Correct is the number of evaluated questions answered correctly.
+	Correct int
+}
+
+// Accuracy returns the fraction of the evaluated questions answered
+// correctly.
+func (r SectionResult) Accuracy() float64 {
+	if r.Evaluated == 0 {
+		return 0
+	}
+	return float64(r.Correct) / float64(r.Evaluated)
+}
+
+// Coverage returns the fraction of the questions that were evaluated.
+func (r SectionResult) Coverage() float64 {
+	if r.Questions == 0 {
+		return 0
+	}
+	return float64(r.Evaluated) / float64(r.Questions)
+}
+
+func (r *SectionResult) add(s SectionResult) {
+	r.Questions += s.Questions
+	r.Evaluated += s.Evaluated
+	r.Correct += s.Correct
+}
+
+// AnalogyResult represents the score of a model on an analogy benchmark.
+type AnalogyResult struct {
+	// Sections holds the score of every section in the benchmark order.
+	Sections []SectionResult
+
+	// Semantic sums the sections whose name does not start with "gram",
+	// following the convention of questions-words.txt.
+	Semantic SectionResult
+
+	// Syntactic sums the sections whose name starts with "gram".
+	Syntactic SectionResult
+
+	// Total sums all the sections.
+	Total SectionResult
+
+	// OOV lists the words that are not among the searched words in
+	// alphabetical order.
+	OOV []string
+}
+
+// Analogy scores the model on the analogy sections. The answer to a question
+// is the word nearest to B - A + C by cosine similarity, excluding A, B and C
+// themselves, searched among the first config.Restrict words of vocabulary.
+// The vocabulary is expected in rank order, so the search covers the most
+// frequent words. Questions with a word outside of the searched words are
+// left out of the accuracy and their words are reported as OOV.
+func Analogy(ctx context.Context, m Model, vocabulary []string, sections []Section, config Config) (AnalogyResult, error) {
+	if config.Vector <= 0 {
+		return AnalogyResult{}, errors.New("vector size must be greater than zero")
+	}
+
+	if config.Restrict > 0 && len(vocabulary) > config.Restrict {
+		vocabulary = vocabulary[:config.Restrict]
+	}
+
+	s := newSearch(m, vocabulary, config.Vector)
+	if len(s.words) == 0 {
+		return AnalogyResult{}, errors.New("no vectors found for the vocabulary")
+	}
+
+	result := AnalogyResult{
+		Sections:  make([]SectionResult, len(sections)),
+		Semantic:  SectionResult{Name: "semantic"},
+		Syntactic: SectionResult{Name: "syntactic"},
+		Total:     SectionResult{Name: "total"},
+	}
+
+	oov := make(map[string]struct{})
+
+	for i, section := range sections {
+		sr := SectionResult{
+			Name:      section.Name,
+			Questions: len(section.Questions),
+		}
+
+		var queries [][4]int
+		for _, q := range section.Questions {
+			query, known := [4]int{}, true
+			for k, word := range [4]string{q.A, q.B, q.C, q.D} {
+				word = config.word(word)
+
+				idx, exists := s.index[word]
+				if !exists {
+					oov[word] = struct{}{}
+					known = false
+				}
+				query[k] = idx
+			}
+
+			if known {
+				queries = append(queries, query)
+			}
+		}
+
+		correct, err := s.answer(ctx, queries, config.Threads)
+		if err != nil {
+			return AnalogyResult{}, err
+		}
+
+		sr.Evaluated = len(queries)
+		sr.Correct = correct
+		result.Sections[i] = sr
+
+		if strings.HasPrefix(section.Name, "gram") {
+			result.Syntactic.add(sr)
+		} else {
+			result.Semantic.add(sr)
+		}
+		result.Total.add(sr)
+	}
+
+	result.OOV = sortedKeys(oov)
+
+	return result, nil
+}
+
+// =============================================================================
+
+// search holds the normalized vectors of the words searched for answers.
+type search struct {
+	size    int
+	words   []string
+	index   map[string]int
+	vectors []float32
+}
+
+func newSearch(m Model, vocabulary []string, size int) *search {
+	s := search{
+		size:  size,
+		index: make(map[string]int, len(vocabulary)),
+	}
+
+	for _, word := range vocabulary {
+		if _, exists := s.index[word]; exists {
+			continue
+		}
+
+		vec := vectorOf(m, word, size)
+		if vec == nil {
+			continue
+		}
+
+		s.index[word] = len(s.words)
+		s.words = append(s.words, word)
+		s.vectors = append(s.vectors, vec...)
+	}
+
+	return &s
+}
+
+func (s *search) vector(i int) []float32 {
+	return s.vectors[i*s.size : (i+1)*s.size]
+}
+
+// answer returns how many of the queries are answered correctly. Every query
+// holds the indexes of the words A, B, C and D of a question.
+func (s *search) answer(ctx context.Context, queries [][4]int, threads int) (int, error) {
+	threads = max(threads, 1)
+
+	var wg sync.WaitGroup
+	corrects := make([]int, threads)
+	errs := make([]error, threads)
+
+	for t := 0; t < threads; t++ {
+		wg.Add(1)
+		go func(t int) {
+			defer wg.Done()
+
+			target := make([]float32, s.size)
+			for i := t; i < len(queries); i += threads {
+				if err := ctx.Err(); err != nil {
+					errs[t] = err
+					return
+				}
+
+				q := queries[i]
+				a, b, c := s.vector(q[0]), s.vector(q[1]), s.vector(q[2])
+				for k := range target {
+					target[k] = b[k] - a[k] + c[k]
+				}
+
+				if s.nearest(target, q[0], q[1], q[2]) == q[3] {
+					corrects[t]++
+				}
+			}
+		}(t)
+	}
+
+	wg.Wait()
+
+	var correct int
+	for t := range corrects {
+		if errs[t] != nil {
+			return 0, errs[t]
+		}
+		correct += corrects[t]
+	}
+
+	return correct, nil
+}
+
+// nearest returns the index of the word with the highest cosine similarity
+// to the target, skipping the excluded words. The target does not need to be
+// normalized as it does not change the order.
+func (s *search) nearest(target []float32, exclude ...int) int {
+	best, bestSim := -1, 0.0
+
+	for i := range s.words {
+		skip := false
+		for _, e := range exclude {
+			if i == e {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+
+		if sim := vector.Dot(target, s.vector(i)); best == -1 || sim > bestSim {
+			best, bestSim = i, sim
+		}
+	}
+
+	return best
+}
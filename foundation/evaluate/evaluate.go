@@ -0,0 +1,108 @@
+// Package evaluate provides intrinsic evaluation of word embeddings against
+// the standard benchmark file formats, so models trained with different
+// configurations can be compared objectively.
+//
+// Word similarity is scored with the Spearman rank correlation between the
+// cosine similarity of the model and the human judgement of word pairs, as
+// found in the WordSim-353 and SimLex-999 files. Analogies are scored with the
+// accuracy of the 3CosAdd method on files in the questions-words.txt format
+// of the original word2vec tool:
+// https://github.com/tmikolov/word2vec/blob/master/questions-words.txt
+//
+// Both evaluations report the coverage of the benchmark by the model, since a
+// score computed over a few known words is not comparable with one computed
+// over the whole benchmark.
+package evaluate
+
+import (
+	"errors"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/ardanlabs/ai-training/foundation/vector"
+)
+
+// Model represents the word vectors to evaluate. It is satisfied by the
+// word2vec and glove models, and by Words for any other vectorized data.
+type Model interface {
+	VectorOf(word string, vector []float32) error
+}
+
+// Words represents a model built from vectorized data keyed by word, such as
+// the embeddings of single words produced by Ollama.
+type Words map[string]vector.Data
+
+// VectorOf copies the vector of the word into vector.
+func (w Words) VectorOf(word string, vector []float32) error {
+	data, exists := w[word]
+	if !exists {
+		return errors.New("unknown tokens")
+	}
+
+	copy(vector, data.Vector())
+
+	return nil
+}
+
+// =============================================================================
+
+// Config defines the settings of an evaluation.
+type Config struct {
+	// Vector represents the number of data points of the model vectors.
+	// Ex: 300
+	Vector int
+
+	// Lowercase represents whether the benchmark words are lowercased before
+	// they are looked up, for models trained on lowercased text.
+	Lowercase bool
+
+	// Restrict represents the number of words, in vocabulary order, searched
+	// for the answer of an analogy. All the words are searched when zero.
+	// Ex: 30000
+	Restrict int
+
+	// Threads represents the number of goroutines answering analogies.
+	Threads int
+}
+
+// NewConfigDefault defines a set of default configuration options.
+func NewConfigDefault() Config {
+	return Config{
+		Vector:    300,
+		Lowercase: true,
+		Restrict:  30000,
+		Threads:   runtime.GOMAXPROCS(0),
+	}
+}
+
+func (c Config) word(word string) string {
+	if c.Lowercase {
+		return strings.ToLower(word)
+	}
+	return word
+}
+
+// =============================================================================
+
+// vectorOf returns the normalized vector of the word or nil when the model
+// has no vector for it.
+func vectorOf(m Model, word string, size int) []float32 {
+	vec := make([]float32, size)
+	if err := m.VectorOf(word, vec); err != nil || !vector.Normalize(vec) {
+		return nil
+	}
+
+	return vec
+}
+
+// sortedKeys returns the words of the set in alphabetical order.
+func sortedKeys(set map[string]struct{}) []string {
+	words := make([]string, 0, len(set))
+	for word := range set {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	return words
+}
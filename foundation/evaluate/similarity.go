@@ -0,0 +1,224 @@
+package evaluate
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ardanlabs/ai-training/foundation/vector"
+)
+
+// Pair represents two words and the human judgement of their similarity.
+type Pair struct {
+	Word1 string
+	Word2 string
+	Score float64
+}
+
+// ReadPairs reads a word similarity benchmark. Every line holds two words and
+// a score separated by tabs, by commas or else by spaces, and the first
+// number after the words is taken as the score. This covers the WordSim-353
+// and SimLex-999 files, whose extra columns are ignored. Empty lines, comments
+// starting with '#' and header lines without a score are skipped.
+func ReadPairs(filePairs string) ([]Pair, error) {
+	f, err := os.Open(filePairs)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	var pairs []Pair
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var fields []string
+		switch {
+		case strings.Contains(line, "\t"):
+			fields = strings.Split(line, "\t")
+		case strings.Contains(line, ","):
+			fields = strings.Split(line, ",")
+		default:
+			fields = strings.Fields(line)
+		}
+
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		if len(fields) < 3 {
+			continue
+		}
+
+		for _, field := range fields[2:] {
+			score, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				continue
+			}
+
+			pairs = append(pairs, Pair{Word1: fields[0], Word2: fields[1], Score: score})
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	if len(pairs) == 0 {
+		return nil, errors.New("no word pairs found")
+	}
+
+	return pairs, nil
+}
+
+// =============================================================================
+
+// SimilarityResult represents the score of a model on a word similarity
+// benchmark.
+type SimilarityResult struct {
+	// Pairs is the number of pairs in the benchmark.
+	Pairs int
+
+	// Evaluated is the number of pairs the model has vectors for.
+	Evaluated int
+
+	// Spearman is the rank correlation between the cosine similarities and
+	// the human scores of the evaluated pairs.
+	Spearman float64
+
+	// OOV lists the words without a vector in alphabetical order.
+	OOV []string
+}
+
+// Coverage returns the fraction of the pairs the model has vectors for.
+func (r SimilarityResult) Coverage() float64 {
+	if r.Pairs == 0 {
+		return 0
+	}
+	return float64(r.Evaluated) / float64(r.Pairs)
+}
+
+// Similarity scores the model on the word pairs. Pairs with a word the model
+// has no vector for are left out of the correlation and reported as OOV. A
+// model composing vectors from subwords therefore covers every pair.
+func Similarity(m Model, pairs []Pair, config Config) (SimilarityResult, error) {
+	if config.Vector <= 0 {
+		return SimilarityResult{}, errors.New("vector size must be greater than zero")
+	}
+
+	result := SimilarityResult{
+		Pairs: len(pairs),
+	}
+
+	vectors := make(map[string][]float32)
+	oov := make(map[string]struct{})
+
+	lookup := func(word string) []float32 {
+		word = config.word(word)
+		if vec, exists := vectors[word]; exists {
+			return vec
+		}
+
+		vec := vectorOf(m, word, config.Vector)
+		if vec == nil {
+			oov[word] = struct{}{}
+		}
+		vectors[word] = vec
+
+		return vec
+	}
+
+	var model, human []float64
+	for _, p := range pairs {
+		v1 := lookup(p.Word1)
+		v2 := lookup(p.Word2)
+		if v1 == nil || v2 == nil {
+			continue
+		}
+
+		model = append(model, vector.Dot(v1, v2))
+		human = append(human, p.Score)
+	}
+
+	result.Evaluated = len(model)
+	result.Spearman = Spearman(model, human)
+	result.OOV = sortedKeys(oov)
+
+	return result, nil
+}
+
+// =============================================================================
+
+// Spearman calculates the Spearman rank correlation of two series of the same
+// length. Tied values get the average of their ranks. It returns NaN when a
+// series has fewer than two values or all its values are equal.
+func Spearman(x, y []float64) float64 {
+	if len(x) != len(y) || len(x) < 2 {
+		return math.NaN()
+	}
+
+	return pearson(ranks(x), ranks(y))
+}
+
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return values[order[i]] < values[order[j]]
+	})
+
+	r := make([]float64, len(values))
+	for i := 0; i < len(order); {
+		j := i + 1
+		for j < len(order) && values[order[j]] == values[order[i]] {
+			j++
+		}
+
+		// Ranks start at one, ties share the average of positions i+1..j.
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			r[order[k]] = rank
+		}
+
+		i = j
+	}
+
+	return r
+}
+
+func pearson(x, y []float64) float64 {
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= float64(len(x))
+	my /= float64(len(y))
+
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx := x[i] - mx
+		dy := y[i] - my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+
+	if sxx == 0 || syy == 0 {
+		return math.NaN()
+	}
+
+	return sxy / math.Sqrt(sxx*syy)
+}
@@ -0,0 +1,347 @@
+package glove
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"slices"
+)
+
+// record represents the weighted number of times the context word j appears
+// in the window of the word i.
+type record struct {
+	i uint32
+	j uint32
+	x float64
+}
+
+const recordSize = 16
+
+func (r record) key() uint64 {
+	return uint64(r.i)<<32 | uint64(r.j)
+}
+
+// cooccurrences holds the co-occurrence matrix either in memory or, when it
+// does not fit in memory, in bucket files of randomly distributed records.
+type cooccurrences struct {
+	records []record
+	buckets []string
+	size    int
+}
+
+// countCooccurrences builds the co-occurrence matrix. A context word at
+// distance d from the word adds 1/d to their count in both directions. Once
+// the matrix grows over the configured number of entries, it is written to
+// a sorted shard file in dir and the shards are merged at the end.
+func countCooccurrences(ctx context.Context, c corpus, voc vocabulary, config Config, dir string) (cooccurrences, error) {
+	counts := make(map[uint64]float64)
+	var shards []string
+
+	defer func() {
+		for _, shard := range shards {
+			os.Remove(shard)
+		}
+	}()
+
+	window := config.Vector.Window
+	maxEntries := config.Cooccurrence.MaxEntries
+
+	var sentence []uint32
+	var sentences int
+
+	err := c.sentences(config.Corpus.Tokenizer, config.Corpus.Sequencer, func(words []string) error {
+		if sentences++; sentences%10000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		// Words out of the vocabulary are dropped before the window is
+		// applied, the same way the word2vec trainer does.
+		sentence = sentence[:0]
+		for _, word := range words {
+			if i, exists := voc.index[word]; exists {
+				sentence = append(sentence, i)
+			}
+		}
+
+		for p, i := range sentence {
+			for q := max(p-window, 0); q < p; q++ {
+				x := 1 / float64(p-q)
+				j := sentence[q]
+				counts[uint64(i)<<32|uint64(j)] += x
+				counts[uint64(j)<<32|uint64(i)] += x
+			}
+		}
+
+		if maxEntries > 0 && len(counts) >= maxEntries {
+			shard, err := writeShard(dir, counts)
+			if err != nil {
+				return err
+			}
+			shards = append(shards, shard)
+			clear(counts)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return cooccurrences{}, err
+	}
+
+	if len(shards) == 0 {
+		co := cooccurrences{
+			records: make([]record, 0, len(counts)),
+		}
+		for key, x := range counts {
+			co.records = append(co.records, record{i: uint32(key >> 32), j: uint32(key), x: x})
+		}
+		co.size = len(co.records)
+
+		return co, nil
+	}
+
+	if len(counts) > 0 {
+		shard, err := writeShard(dir, counts)
+		if err != nil {
+			return cooccurrences{}, err
+		}
+		shards = append(shards, shard)
+		clear(counts)
+	}
+
+	return mergeShards(ctx, dir, shards)
+}
+
+// writeShard writes the counts sorted by word and context word.
+func writeShard(dir string, counts map[uint64]float64) (string, error) {
+	keys := make([]uint64, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	f, err := os.CreateTemp(dir, "shard-*.bin")
+	if err != nil {
+		return "", fmt.Errorf("create shard: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, key := range keys {
+		if err := writeRecord(w, record{i: uint32(key >> 32), j: uint32(key), x: counts[key]}); err != nil {
+			return "", fmt.Errorf("write shard: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("write shard: %w", err)
+	}
+
+	return f.Name(), nil
+}
+
+// mergeShards sums the counts of the sorted shards and scatters the merged
+// records randomly over as many bucket files as there are shards, so every
+// bucket fits in memory and can be shuffled for training.
+func mergeShards(ctx context.Context, dir string, shards []string) (cooccurrences, error) {
+	var co cooccurrences
+
+	h := make(shardHeap, 0, len(shards))
+	for _, shard := range shards {
+		f, err := os.Open(shard)
+		if err != nil {
+			return cooccurrences{}, fmt.Errorf("open shard: %w", err)
+		}
+		defer f.Close()
+
+		sr := shardReader{r: bufio.NewReader(f)}
+		ok, err := sr.next()
+		if err != nil {
+			return cooccurrences{}, err
+		}
+		if ok {
+			h = append(h, &sr)
+		}
+	}
+	heap.Init(&h)
+
+	writers := make([]*bufio.Writer, len(shards))
+	for i := range writers {
+		f, err := os.CreateTemp(dir, "bucket-*.bin")
+		if err != nil {
+			return cooccurrences{}, fmt.Errorf("create bucket: %w", err)
+		}
+		defer f.Close()
+
+		co.buckets = append(co.buckets, f.Name())
+		writers[i] = bufio.NewWriter(f)
+	}
+
+	var merged record
+	var started bool
+
+	emit := func(r record) error {
+		co.size++
+		if co.size%1000000 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		if err := writeRecord(writers[rand.IntN(len(writers))], r); err != nil {
+			return fmt.Errorf("write bucket: %w", err)
+		}
+		return nil
+	}
+
+	for h.Len() > 0 {
+		sr := h[0]
+		r := sr.current
+
+		switch {
+		case !started:
+			merged = r
+			started = true
+
+		case merged.key() == r.key():
+			merged.x += r.x
+
+		default:
+			if err := emit(merged); err != nil {
+				return cooccurrences{}, err
+			}
+			merged = r
+		}
+
+		ok, err := sr.next()
+		if err != nil {
+			return cooccurrences{}, err
+		}
+		if ok {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+
+	if started {
+		if err := emit(merged); err != nil {
+			return cooccurrences{}, err
+		}
+	}
+
+	for _, w := range writers {
+		if err := w.Flush(); err != nil {
+			return cooccurrences{}, fmt.Errorf("write bucket: %w", err)
+		}
+	}
+
+	return co, nil
+}
+
+// chunks calls fn with the records shuffled in chunks that fit in memory.
+// The chunk passed to fn is reused between calls.
+func (co cooccurrences) chunks(fn func(records []record) error) error {
+	if co.buckets == nil {
+		rand.Shuffle(len(co.records), func(i, j int) {
+			co.records[i], co.records[j] = co.records[j], co.records[i]
+		})
+		return fn(co.records)
+	}
+
+	var records []record
+	for _, i := range rand.Perm(len(co.buckets)) {
+		var err error
+		if records, err = readBucket(co.buckets[i], records[:0]); err != nil {
+			return err
+		}
+
+		rand.Shuffle(len(records), func(i, j int) {
+			records[i], records[j] = records[j], records[i]
+		})
+
+		if err := fn(records); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readBucket(bucket string, records []record) ([]record, error) {
+	f, err := os.Open(bucket)
+	if err != nil {
+		return nil, fmt.Errorf("open bucket: %w", err)
+	}
+	defer f.Close()
+
+	sr := shardReader{r: bufio.NewReader(f)}
+	for {
+		ok, err := sr.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return records, nil
+		}
+		records = append(records, sr.current)
+	}
+}
+
+// =============================================================================
+
+func writeRecord(w io.Writer, r record) error {
+	var buf [recordSize]byte
+	binary.LittleEndian.PutUint32(buf[0:], r.i)
+	binary.LittleEndian.PutUint32(buf[4:], r.j)
+	binary.LittleEndian.PutUint64(buf[8:], math.Float64bits(r.x))
+
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// shardReader reads records one at a time.
+type shardReader struct {
+	r       io.Reader
+	current record
+}
+
+func (sr *shardReader) next() (bool, error) {
+	var buf [recordSize]byte
+	if _, err := io.ReadFull(sr.r, buf[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		return false, fmt.Errorf("read records: %w", err)
+	}
+
+	sr.current = record{
+		i: binary.LittleEndian.Uint32(buf[0:]),
+		j: binary.LittleEndian.Uint32(buf[4:]),
+		x: math.Float64frombits(binary.LittleEndian.Uint64(buf[8:])),
+	}
+
+	return true, nil
+}
+
+// shardHeap orders the shard readers by their current record.
+type shardHeap []*shardReader
+
+func (h shardHeap) Len() int           { return len(h) }
+func (h shardHeap) Less(i, j int) bool { return h[i].current.key() < h[j].current.key() }
+func (h shardHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *shardHeap) Push(x any)        { *h = append(*h, x.(*shardReader)) }
+func (h *shardHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
@@ -0,0 +1,199 @@
+package glove
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// corpus provides repeated reads of the train data, which is either a file
+// or the content read from the input and kept in memory.
+type corpus struct {
+	file string
+	data []byte
+}
+
+func newCorpus(config ConfigCorpus) (corpus, error) {
+	if config.InputFile != "" {
+		return corpus{file: config.InputFile}, nil
+	}
+
+	if config.Input == nil {
+		return corpus{}, errors.New("no input provided")
+	}
+
+	data, err := io.ReadAll(config.Input)
+	if err != nil {
+		return corpus{}, fmt.Errorf("read input: %w", err)
+	}
+
+	if len(data) == 0 {
+		return corpus{}, errors.New("input is empty")
+	}
+
+	return corpus{data: data}, nil
+}
+
+// sentences calls fn for every sentence of the corpus. The words slice is
+// reused between calls.
+func (c corpus) sentences(tokenizer string, sequencer string, fn func(words []string) error) error {
+	if c.file == "" {
+		return readSentences(bytes.NewReader(c.data), tokenizer, sequencer, fn)
+	}
+
+	f, err := os.Open(c.file)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	return readSentences(f, tokenizer, sequencer, fn)
+}
+
+// readSentences splits the text the same way the word2vec trainer does. Any
+// byte of the tokenizer ends a word and any byte of the sequencer, which
+// should be part of the tokenizer too, ends a sentence.
+func readSentences(r io.Reader, tokenizer string, sequencer string, fn func(words []string) error) error {
+	br := bufio.NewReaderSize(r, 1024*1024)
+
+	var words []string
+	var word []byte
+
+	flushWord := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+
+	flushSentence := func() error {
+		flushWord()
+		if len(words) == 0 {
+			return nil
+		}
+
+		err := fn(words)
+		words = words[:0]
+
+		return err
+	}
+
+	for {
+		ch, err := br.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("read corpus: %w", err)
+		}
+
+		switch {
+		case strings.IndexByte(sequencer, ch) >= 0:
+			if err := flushSentence(); err != nil {
+				return err
+			}
+
+		case strings.IndexByte(tokenizer, ch) >= 0:
+			flushWord()
+
+		default:
+			word = append(word, ch)
+		}
+	}
+
+	return flushSentence()
+}
+
+// =============================================================================
+
+// vocabulary holds the words kept for training ordered from the more
+// frequent to the less frequent ones.
+type vocabulary struct {
+	words []string
+	freq  []int
+	index map[string]uint32
+}
+
+func buildVocabulary(c corpus, config Config) (vocabulary, int, error) {
+	stopWords, err := loadStopWords(config.Corpus)
+	if err != nil {
+		return vocabulary{}, 0, err
+	}
+
+	counts := make(map[string]int)
+	var totalWords int
+
+	err = c.sentences(config.Corpus.Tokenizer, config.Corpus.Sequencer, func(words []string) error {
+		for _, word := range words {
+			totalWords++
+			if _, exists := stopWords[word]; exists {
+				continue
+			}
+			counts[word]++
+		}
+		return nil
+	})
+	if err != nil {
+		return vocabulary{}, 0, err
+	}
+
+	var voc vocabulary
+	for word, freq := range counts {
+		if freq < config.Vector.Frequency {
+			continue
+		}
+		voc.words = append(voc.words, word)
+	}
+
+	sort.Slice(voc.words, func(i, j int) bool {
+		if counts[voc.words[i]] != counts[voc.words[j]] {
+			return counts[voc.words[i]] > counts[voc.words[j]]
+		}
+		return voc.words[i] < voc.words[j]
+	})
+
+	voc.freq = make([]int, len(voc.words))
+	voc.index = make(map[string]uint32, len(voc.words))
+	for i, word := range voc.words {
+		voc.freq[i] = counts[word]
+		voc.index[word] = uint32(i)
+	}
+
+	return voc, totalWords, nil
+}
+
+// loadStopWords reads the stop words from the file and joins them with the
+// ones kept in memory.
+func loadStopWords(config ConfigCorpus) (map[string]struct{}, error) {
+	stopWords := make(map[string]struct{}, len(config.StopWords))
+	for _, word := range config.StopWords {
+		stopWords[word] = struct{}{}
+	}
+
+	if config.StopWordsFile == "" {
+		return stopWords, nil
+	}
+
+	f, err := os.Open(config.StopWordsFile)
+	if err != nil {
+		return nil, fmt.Errorf("open stop words: %w", err)
+	}
+	defer f.Close()
+
+	err = readSentences(f, config.Tokenizer, config.Sequencer, func(words []string) error {
+		for _, word := range words {
+			stopWords[word] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read stop words: %w", err)
+	}
+
+	return stopWords, nil
+}
@@ -0,0 +1,375 @@
+// Package glove provides support for training count-based word embeddings
+// with the GloVe model, as an alternative to the predictive word2vec model:
+// https://nlp.stanford.edu/projects/glove/
+//
+// Training builds a windowed co-occurrence matrix from the corpus, which is
+// sharded to disk when it grows too large, and fits the word vectors to the
+// logarithm of the co-occurrence counts with AdaGrad. The vectors are written
+// in the binary format of the original word2vec tool, so the model can be
+// loaded by word2vec.Load as well as by Load in this package.
+package glove
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// ConfigCorpus represents the base config items. It mirrors the word2vec
+// corpus configuration, so both models are trained from the same data.
+type ConfigCorpus struct {
+	// InputFile represents the file of the raw data to process.
+	InputFile string
+
+	// Input represents the raw data to process when InputFile is empty. It is
+	// read to the end and kept in memory for the training.
+	Input io.Reader
+
+	// StopWordsFile represents the file of the stopwords to use.
+	StopWordsFile string
+
+	// StopWords represents the set of stopwords to use in memory. They are
+	// skipped along with the ones from StopWordsFile.
+	StopWords []string
+
+	// Tokenizer represents the word delimiter.
+	// Ex: " \n,.-!?:;/\"#$%&'()*+<=>@[]\\^_`{|}~\t\v\f\r"
+	Tokenizer string
+
+	// Sequencer represents the end of a sentence. Context windows never
+	// cross the end of a sentence.
+	// Ex: ".\n?!"
+	Sequencer string
+}
+
+// ConfigWordVector represents word related config items.
+type ConfigWordVector struct {
+	// Vector represents the number of data points for the vector.
+	// Ex: 300
+	Vector int
+
+	// Window represents the max distance between a word and the context
+	// words counted as co-occurring with it.
+	// Ex: 10
+	Window int
+
+	// Frequency represents when words should be discarded that appear less
+	// than <int> times.
+	// Ex: 5
+	Frequency int
+}
+
+// ConfigCooccurrence represents co-occurrence matrix related config items.
+type ConfigCooccurrence struct {
+	// MaxEntries represents the number of matrix entries kept in memory
+	// before they are written to a shard file. Every entry takes around 50
+	// bytes. The matrix is kept in memory when it is zero.
+	// Ex: 20000000
+	MaxEntries int
+
+	// ShardDir represents the directory for the shard files, which are
+	// removed once the training is over. Defaults to os.TempDir.
+	ShardDir string
+}
+
+// ConfigLearning represents learning related config items.
+type ConfigLearning struct {
+	// Epoch represents the number of training runs.
+	// Ex: 25
+	Epoch int
+
+	// Rate represents the initial learning rate of AdaGrad.
+	// Ex: 0.05
+	Rate float64
+
+	// XMax represents the co-occurrence count the weighting function stops
+	// growing at, so frequent pairs do not dominate the training.
+	// Ex: 100
+	XMax float64
+
+	// Alpha represents the exponent of the weighting function.
+	// Ex: 0.75
+	Alpha float64
+}
+
+// Config defines the required setting for training.
+type Config struct {
+	Corpus       ConfigCorpus
+	Vector       ConfigWordVector
+	Cooccurrence ConfigCooccurrence
+	Learning     ConfigLearning
+
+	Output  string
+	Threads int
+	Verbose bool
+}
+
+// NewConfigDefault defines a set of default configuration options.
+func NewConfigDefault() Config {
+	return Config{
+		Corpus: ConfigCorpus{
+			Tokenizer: " \n,.-!?:;/\"#$%&'()*+<=>@[]\\^_`{|}~\t\v\f\r",
+			Sequencer: ".\n?!",
+		},
+		Vector: ConfigWordVector{
+			Vector:    300,
+			Window:    10,
+			Frequency: 5,
+		},
+		Cooccurrence: ConfigCooccurrence{
+			MaxEntries: 20000000,
+		},
+		Learning: ConfigLearning{
+			Epoch: 25,
+			Rate:  0.05,
+			XMax:  100,
+			Alpha: 0.75,
+		},
+		Threads: runtime.GOMAXPROCS(0),
+		Verbose: true,
+	}
+}
+
+// =============================================================================
+
+// Train performs a training run and writes the model to config.Output.
+// Cancelling the context stops the training and no model is written.
+func Train(ctx context.Context, config Config) error {
+	switch {
+	case config.Vector.Vector <= 0:
+		return errors.New("vector size must be greater than zero")
+	case config.Vector.Window <= 0:
+		return errors.New("window must be greater than zero")
+	case config.Learning.XMax <= 0:
+		return errors.New("xmax must be greater than zero")
+	case config.Output == "":
+		return errors.New("no output provided")
+	}
+
+	if config.Verbose {
+		printConfig(config)
+	}
+
+	c, err := newCorpus(config.Corpus)
+	if err != nil {
+		return err
+	}
+
+	voc, totalWords, err := buildVocabulary(c, config)
+	if err != nil {
+		return err
+	}
+
+	if len(voc.words) == 0 {
+		return errors.New("vocabulary is empty")
+	}
+
+	if config.Verbose {
+		fmt.Printf("Vocabulary size: %d\nTotal words: %d\n\n", len(voc.words), totalWords)
+	}
+
+	dir, err := os.MkdirTemp(config.Cooccurrence.ShardDir, "glove-")
+	if err != nil {
+		return fmt.Errorf("create shard dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	co, err := countCooccurrences(ctx, c, voc, config, dir)
+	if err != nil {
+		return fmt.Errorf("count co-occurrences: %w", err)
+	}
+
+	if config.Verbose {
+		fmt.Printf("Co-occurrences: %d in %d bucket(s)\n\n", co.size, max(len(co.buckets), 1))
+	}
+
+	m := newModel(len(voc.words), config.Vector.Vector)
+
+	for epoch := 1; epoch <= config.Learning.Epoch; epoch++ {
+		var cost float64
+
+		err := co.chunks(func(records []record) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			cost += m.train(records, config)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if config.Verbose {
+			fmt.Printf("epoch: %d, cost: %.6f\n", epoch, cost/float64(max(co.size, 1)))
+		}
+	}
+
+	if config.Verbose {
+		fmt.Print("\n")
+	}
+
+	if err := m.save(config.Output, voc); err != nil {
+		return fmt.Errorf("save model: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// model holds the parameters being trained: the word and context vectors,
+// their biases and the AdaGrad sums of squared gradients of each of them.
+type model struct {
+	size int
+
+	w  []float64
+	c  []float64
+	bw []float64
+	bc []float64
+
+	gw  []float64
+	gc  []float64
+	gbw []float64
+	gbc []float64
+}
+
+func newModel(words int, size int) *model {
+	m := model{
+		size: size,
+		w:    make([]float64, words*size),
+		c:    make([]float64, words*size),
+		bw:   make([]float64, words),
+		bc:   make([]float64, words),
+		gw:   make([]float64, words*size),
+		gc:   make([]float64, words*size),
+		gbw:  make([]float64, words),
+		gbc:  make([]float64, words),
+	}
+
+	for _, params := range [][]float64{m.w, m.c, m.bw, m.bc} {
+		for i := range params {
+			params[i] = (rand.Float64() - 0.5) / float64(size)
+		}
+	}
+
+	for _, grads := range [][]float64{m.gw, m.gc, m.gbw, m.gbc} {
+		for i := range grads {
+			grads[i] = 1
+		}
+	}
+
+	return &m
+}
+
+// train runs one pass over the records and returns the sum of their costs.
+// A record updates the vector of its word and the context vector of its
+// context word, so the records are split in threads x threads blocks by the
+// two. The blocks are trained in rounds where no two threads share a word or
+// a context word, which lets them update the parameters without locking.
+func (m *model) train(records []record, config Config) float64 {
+	threads := max(config.Threads, 1)
+
+	blocks := make([][]record, threads*threads)
+	for _, r := range records {
+		b := int(r.i)%threads*threads + int(r.j)%threads
+		blocks[b] = append(blocks[b], r)
+	}
+
+	costs := make([]float64, threads)
+
+	for round := 0; round < threads; round++ {
+		var wg sync.WaitGroup
+		wg.Add(threads)
+
+		for t := 0; t < threads; t++ {
+			go func(t int, records []record) {
+				defer wg.Done()
+				costs[t] += m.trainRecords(records, config.Learning)
+			}(t, blocks[t*threads+(t+round)%threads])
+		}
+
+		wg.Wait()
+	}
+
+	var cost float64
+	for _, c := range costs {
+		cost += c
+	}
+
+	return cost
+}
+
+func (m *model) trainRecords(records []record, config ConfigLearning) float64 {
+	var cost float64
+
+	for _, r := range records {
+		wi := m.w[int(r.i)*m.size : (int(r.i)+1)*m.size]
+		cj := m.c[int(r.j)*m.size : (int(r.j)+1)*m.size]
+		gwi := m.gw[int(r.i)*m.size : (int(r.i)+1)*m.size]
+		gcj := m.gc[int(r.j)*m.size : (int(r.j)+1)*m.size]
+
+		diff := m.bw[r.i] + m.bc[r.j] - math.Log(r.x)
+		for k := range wi {
+			diff += wi[k] * cj[k]
+		}
+
+		fdiff := diff
+		if r.x < config.XMax {
+			fdiff *= math.Pow(r.x/config.XMax, config.Alpha)
+		}
+
+		if math.IsNaN(fdiff) || math.IsInf(fdiff, 0) {
+			continue
+		}
+
+		cost += 0.5 * fdiff * diff
+		fdiff *= config.Rate
+
+		for k := range wi {
+			g1 := fdiff * cj[k]
+			g2 := fdiff * wi[k]
+
+			wi[k] -= g1 / math.Sqrt(gwi[k])
+			cj[k] -= g2 / math.Sqrt(gcj[k])
+
+			gwi[k] += g1 * g1
+			gcj[k] += g2 * g2
+		}
+
+		m.bw[r.i] -= fdiff / math.Sqrt(m.gbw[r.i])
+		m.bc[r.j] -= fdiff / math.Sqrt(m.gbc[r.j])
+
+		fdiff *= fdiff
+		m.gbw[r.i] += fdiff
+		m.gbc[r.j] += fdiff
+	}
+
+	return cost
+}
+
+func printConfig(config Config) {
+	input := config.Corpus.InputFile
+	if input == "" {
+		input = "<memory>"
+	}
+	fmt.Println("Train data file:", input)
+	fmt.Println("Output model file:", config.Output)
+	fmt.Println("Stop-words file:", config.Corpus.StopWordsFile)
+	fmt.Println("Training model: GloVe")
+	fmt.Println("Number of training threads:", config.Threads)
+	fmt.Println("Number of training iterations:", config.Learning.Epoch)
+	fmt.Println("Min word frequency:", config.Vector.Frequency)
+	fmt.Println("Vector size:", config.Vector.Vector)
+	fmt.Println("Max skip length:", config.Vector.Window)
+	fmt.Println("Max co-occurrences in memory:", config.Cooccurrence.MaxEntries)
+	fmt.Println("Starting learning rate:", config.Learning.Rate)
+	fmt.Printf("Weighting function: xmax = %g, alpha = %g\n", config.Learning.XMax, config.Learning.Alpha)
+	fmt.Print("\n")
+}
@@ -0,0 +1,169 @@
+package glove
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// save writes the sum of the word and context vectors, as recommended by the
+// GloVe paper, in the binary format of the word2vec tool: a "<words> <size>"
+// header line followed by every word, a space, its little endian float32
+// vector and a new line. Words are written from the more frequent to the
+// less frequent ones.
+func (m *model) save(fileModel string, voc vocabulary) error {
+	f, err := os.Create(fileModel)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "%d %d\n", len(voc.words), m.size)
+
+	buf := make([]byte, 4*m.size)
+	for i, word := range voc.words {
+		for k := 0; k < m.size; k++ {
+			v := float32(m.w[i*m.size+k] + m.c[i*m.size+k])
+			binary.LittleEndian.PutUint32(buf[4*k:], math.Float32bits(v))
+		}
+
+		w.WriteString(word)
+		w.WriteByte(' ')
+		w.Write(buf)
+		w.WriteByte('\n')
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// Model represents word vectors read from a file in the binary word2vec
+// format, written by Train or by word2vec.Train. A Model is safe for
+// concurrent use by multiple goroutines.
+type Model struct {
+	size    int
+	words   []string
+	index   map[string]int
+	vectors []float32
+}
+
+// Load reads a model written in the binary word2vec format. The vectors are
+// kept as they are stored in the file.
+func Load(fileModel string) (*Model, error) {
+	f, err := os.Open(fileModel)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+
+	header, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	fields := strings.Fields(header)
+	if len(fields) != 2 {
+		return nil, errors.New("wrong model file format")
+	}
+
+	words, err := strconv.Atoi(fields[0])
+	if err != nil || words < 0 {
+		return nil, errors.New("wrong model file format")
+	}
+
+	size, err := strconv.Atoi(fields[1])
+	if err != nil || size <= 0 {
+		return nil, errors.New("wrong model file format")
+	}
+
+	// Every word takes at least a character, a space and its vector, so a
+	// header announcing more than the file holds is refused before the
+	// vectors are allocated.
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat file: %w", err)
+	}
+
+	remaining := info.Size() - int64(len(header))
+	if int64(size) > remaining/4 || int64(words) > remaining/(4*int64(size)+2) {
+		return nil, fmt.Errorf("header of %d words of size %d is larger than the file", words, size)
+	}
+
+	m := Model{
+		size:    size,
+		words:   make([]string, 0, words),
+		index:   make(map[string]int, words),
+		vectors: make([]float32, words*size),
+	}
+
+	buf := make([]byte, 4*size)
+	for i := 0; i < words; i++ {
+		word, err := r.ReadString(' ')
+		if err != nil {
+			return nil, fmt.Errorf("word %d: %w", i, err)
+		}
+		word = strings.TrimLeft(strings.TrimSuffix(word, " "), "\n")
+
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("word %d: %w", i, err)
+		}
+
+		vec := m.vectors[i*size : (i+1)*size]
+		for k := range vec {
+			vec[k] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*k:]))
+		}
+
+		m.words = append(m.words, word)
+		m.index[word] = i
+	}
+
+	return &m, nil
+}
+
+// Size returns the number of words in the model.
+func (m *Model) Size() int {
+	return len(m.words)
+}
+
+// VectorSize returns the number of data points of the vectors.
+func (m *Model) VectorSize() int {
+	return m.size
+}
+
+// Words returns the words of the model in the order they are stored, which is
+// from the more frequent to the less frequent ones for the trained models.
+func (m *Model) Words() []string {
+	return m.words
+}
+
+// Contains reports whether the word is part of the model.
+func (m *Model) Contains(word string) bool {
+	_, exists := m.index[word]
+	return exists
+}
+
+// VectorOf copies the vector of the word into vector.
+func (m *Model) VectorOf(word string, vector []float32) error {
+	i, exists := m.index[word]
+	if !exists {
+		return errors.New("unknown tokens")
+	}
+
+	copy(vector, m.vectors[i*m.size:(i+1)*m.size])
+
+	return nil
+}
@@ -0,0 +1,170 @@
+// Package keywords provides support for extracting the key terms of a
+// document, like the ones a book chunk or a review is tagged with for display
+// or for lexical filters.
+//
+// Three methods are provided. TF-IDF ranks the words of a document by how
+// often they are found in it and how rare they are in a corpus of documents.
+// RAKE ranks the phrases found between stop words and punctuation by the
+// co-occurrence of their words. TextRank ranks the words by their centrality
+// in a graph of the words found close to each other, and joins the top words
+// that are adjacent in the document into phrases. RAKE and TextRank work on a
+// single document, without a corpus.
+package keywords
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/ardanlabs/ai-training/foundation/stopwords"
+	"github.com/ardanlabs/ai-training/foundation/tokenizer"
+)
+
+// Keyword represents a term of a document and its score. Scores are only
+// comparable between the keywords of the same method and document.
+type Keyword struct {
+	Text  string
+	Score float64
+}
+
+// String implements the fmt.Stringer interface.
+func (k Keyword) String() string {
+	return fmt.Sprintf("%s (%.3f)", k.Text, k.Score)
+}
+
+// =============================================================================
+
+// Config defines the settings of an extractor.
+type Config struct {
+	// StopWords represents the stop words that split the phrases and are
+	// never keywords. A nil filter means the English stop words.
+	// Ex: stopwords.NewFilter("en")
+	StopWords *stopwords.Filter
+
+	// MinLength represents the min number of letters of a keyword, which
+	// keeps out leftovers like single letters.
+	// Ex: 3
+	MinLength int
+
+	// MaxWords represents the max number of words of a RAKE or TextRank
+	// phrase. Longer phrases are dropped, as they are rarely key terms.
+	// Ex: 3
+	MaxWords int
+
+	// Window represents the number of words, stop words aside, that are
+	// linked in the TextRank graph.
+	// Ex: 2
+	Window int
+}
+
+// NewConfigDefault defines a set of default configuration options.
+func NewConfigDefault() Config {
+	return Config{
+		StopWords: nil,
+		MinLength: 3,
+		MaxWords:  3,
+		Window:    2,
+	}
+}
+
+// Extractor extracts the keywords of documents. It keeps the document
+// frequencies of the corpus added for TF-IDF. It is safe for concurrent use.
+type Extractor struct {
+	config    Config
+	tokenizer *tokenizer.Tokenizer
+
+	mu   sync.RWMutex
+	docs int
+	df   map[string]int
+}
+
+// New constructs an extractor with the specified configuration.
+func New(config Config) (*Extractor, error) {
+	if config.StopWords == nil {
+		f, err := stopwords.NewFilter("en")
+		if err != nil {
+			return nil, err
+		}
+		config.StopWords = f
+	}
+
+	if config.MaxWords <= 0 {
+		config.MaxWords = 1
+	}
+
+	if config.Window <= 0 {
+		config.Window = 1
+	}
+
+	tknConfig := tokenizer.NewConfigDefault()
+	tknConfig.Punctuation = true
+
+	e := Extractor{
+		config:    config,
+		tokenizer: tokenizer.New(tknConfig),
+		df:        make(map[string]int),
+	}
+
+	return &e, nil
+}
+
+// =============================================================================
+
+// phrases returns the runs of candidate words of the text, split at stop
+// words, punctuation and tokens like numbers and URLs.
+func (e *Extractor) phrases(text string) [][]string {
+	var phrases [][]string
+	var phrase []string
+
+	split := func() {
+		if len(phrase) > 0 {
+			phrases = append(phrases, phrase)
+			phrase = nil
+		}
+	}
+
+	for _, tkn := range e.tokenizer.Tokenize(text) {
+		if !e.candidate(tkn) {
+			split()
+			continue
+		}
+		phrase = append(phrase, tkn.Text)
+	}
+	split()
+
+	return phrases
+}
+
+// candidate reports whether the token can be part of a keyword.
+func (e *Extractor) candidate(tkn tokenizer.Token) bool {
+	switch tkn.Kind {
+	case tokenizer.Word, tokenizer.Identifier:
+	default:
+		return false
+	}
+
+	if utf8.RuneCountInString(tkn.Text) < e.config.MinLength {
+		return false
+	}
+
+	return !e.config.StopWords.Contains(tkn.Text)
+}
+
+// rank sorts the keywords from the highest score, breaking ties in
+// alphabetical order, and returns the first n, or all if n is 0.
+func rank(keywords []Keyword, n int) []Keyword {
+	slices.SortFunc(keywords, func(a, b Keyword) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Text, b.Text)
+	})
+
+	if n > 0 && len(keywords) > n {
+		keywords = keywords[:n]
+	}
+
+	return keywords
+}
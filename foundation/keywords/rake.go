@@ -0,0 +1,53 @@
+package keywords
+
+import (
+	"strings"
+)
+
+// RAKE returns the n phrases of the document with the highest score of the
+// Rapid Automatic Keyword Extraction method, or all of them if n is 0:
+// https://doi.org/10.1002/9780470689646.ch1
+//
+// The candidate phrases are the runs of words between stop words and
+// punctuation. Every word is scored by its degree, the number of words it is
+// found with in the phrases including itself, over its frequency, which
+// favors the words of longer phrases. A phrase scores the sum of its words.
+func (e *Extractor) RAKE(doc string, n int) []Keyword {
+	var phrases [][]string
+	for _, phrase := range e.phrases(doc) {
+		if len(phrase) <= e.config.MaxWords {
+			phrases = append(phrases, phrase)
+		}
+	}
+
+	freq := make(map[string]float64)
+	degree := make(map[string]float64)
+
+	for _, phrase := range phrases {
+		for _, word := range phrase {
+			freq[word]++
+			degree[word] += float64(len(phrase))
+		}
+	}
+
+	scores := make(map[string]float64)
+	for _, phrase := range phrases {
+		text := strings.Join(phrase, " ")
+		if _, exists := scores[text]; exists {
+			continue
+		}
+
+		var score float64
+		for _, word := range phrase {
+			score += degree[word] / freq[word]
+		}
+		scores[text] = score
+	}
+
+	keywords := make([]Keyword, 0, len(scores))
+	for text, score := range scores {
+		keywords = append(keywords, Keyword{Text: text, Score: score})
+	}
+
+	return rank(keywords, n)
+}
@@ -0,0 +1,143 @@
+package keywords
+
+import (
+	"math"
+	"strings"
+)
+
+// TextRank returns the n keywords of the document with the highest score of
+// the TextRank method, or all of them if n is 0:
+// https://aclanthology.org/W04-3252
+//
+// The words are the vertices of a graph, linked when they are found within
+// Window words of each other once the stop words are removed. The words are
+// ranked by PageRank over that graph, and the top third of them are the
+// keywords. Keywords adjacent in the document are joined into a phrase that
+// scores the sum of its words.
+func (e *Extractor) TextRank(doc string, n int) []Keyword {
+	phrases := e.phrases(doc)
+
+	// The words of the document in order, stop words aside, to link the
+	// words within the window.
+	var seq []string
+	for _, phrase := range phrases {
+		seq = append(seq, phrase...)
+	}
+
+	scores := pageRank(cooccurrence(seq, e.config.Window))
+	if len(scores) == 0 {
+		return nil
+	}
+
+	// The top third of the words are the keywords, and at least one.
+	words := make([]Keyword, 0, len(scores))
+	for word, score := range scores {
+		words = append(words, Keyword{Text: word, Score: score})
+	}
+	words = rank(words, max(1, len(words)/3))
+
+	top := make(map[string]float64, len(words))
+	for _, kw := range words {
+		top[kw.Text] = kw.Score
+	}
+
+	// Runs of keywords in the phrases become multi word keywords.
+	keywords := make(map[string]float64)
+
+	for _, phrase := range phrases {
+		var run []string
+		var score float64
+
+		flush := func() {
+			if len(run) > 0 && len(run) <= e.config.MaxWords {
+				keywords[strings.Join(run, " ")] = score
+			}
+			run, score = nil, 0
+		}
+
+		for _, word := range phrase {
+			s, exists := top[word]
+			if !exists {
+				flush()
+				continue
+			}
+			run = append(run, word)
+			score += s
+		}
+		flush()
+	}
+
+	result := make([]Keyword, 0, len(keywords))
+	for text, score := range keywords {
+		result = append(result, Keyword{Text: text, Score: score})
+	}
+
+	return rank(result, n)
+}
+
+// =============================================================================
+
+// cooccurrence returns the undirected graph of the words found within window
+// words of each other.
+func cooccurrence(seq []string, window int) map[string]map[string]struct{} {
+	graph := make(map[string]map[string]struct{})
+
+	link := func(a, b string) {
+		if graph[a] == nil {
+			graph[a] = make(map[string]struct{})
+		}
+		graph[a][b] = struct{}{}
+	}
+
+	for i, word := range seq {
+		if graph[word] == nil {
+			graph[word] = make(map[string]struct{})
+		}
+
+		for j := i + 1; j <= i+window && j < len(seq); j++ {
+			if seq[j] != word {
+				link(word, seq[j])
+				link(seq[j], word)
+			}
+		}
+	}
+
+	return graph
+}
+
+// pageRank returns the PageRank score of every vertex of the graph, with the
+// damping factor of the TextRank paper, iterating until the scores converge.
+func pageRank(graph map[string]map[string]struct{}) map[string]float64 {
+	const (
+		damping    = 0.85
+		tolerance  = 1e-6
+		iterations = 100
+	)
+
+	scores := make(map[string]float64, len(graph))
+	for word := range graph {
+		scores[word] = 1
+	}
+
+	for range iterations {
+		next := make(map[string]float64, len(graph))
+
+		var delta float64
+		for word, links := range graph {
+			var sum float64
+			for other := range links {
+				sum += scores[other] / float64(len(graph[other]))
+			}
+
+			next[word] = (1 - damping) + damping*sum
+			delta = max(delta, math.Abs(next[word]-scores[word]))
+		}
+
+		scores = next
+		if delta < tolerance {
+			break
+		}
+	}
+
+	return scores
+}
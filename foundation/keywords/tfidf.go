@@ -0,0 +1,64 @@
+package keywords
+
+import (
+	"math"
+)
+
+// Add adds documents to the corpus the TF-IDF scores are computed with, like
+// every chunk of a book or every review of a product. Only the number of
+// documents holding every word is kept.
+func (e *Extractor) Add(docs ...string) {
+	for _, doc := range docs {
+		seen := make(map[string]struct{})
+		for _, phrase := range e.phrases(doc) {
+			for _, word := range phrase {
+				seen[word] = struct{}{}
+			}
+		}
+
+		e.mu.Lock()
+		e.docs++
+		for word := range seen {
+			e.df[word]++
+		}
+		e.mu.Unlock()
+	}
+}
+
+// Docs returns the number of documents of the corpus.
+func (e *Extractor) Docs() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.docs
+}
+
+// TFIDF returns the n words of the document with the highest TF-IDF score, or
+// all of them if n is 0. The term frequency is the share of the words of the
+// document that are the word, and the inverse document frequency is smoothed
+// so a word missing from the corpus, or found in every document, still
+// counts. Without a corpus the words are ranked by their frequency.
+func (e *Extractor) TFIDF(doc string, n int) []Keyword {
+	counts := make(map[string]int)
+	var total int
+
+	for _, phrase := range e.phrases(doc) {
+		for _, word := range phrase {
+			counts[word]++
+			total++
+		}
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	keywords := make([]Keyword, 0, len(counts))
+	for word, count := range counts {
+		idf := math.Log(float64(1+e.docs)/float64(1+e.df[word])) + 1
+		tf := float64(count) / float64(total)
+
+		keywords = append(keywords, Keyword{Text: word, Score: tf * idf})
+	}
+
+	return rank(keywords, n)
+}
@@ -0,0 +1,331 @@
+// Package langdetect provides support for identifying the language of a text,
+// so the stop word list, stemmer or embedding model of that language can be
+// selected for it.
+//
+// Every language has a profile of the character n-grams, of one to three
+// letters, found in its words. A text is scored against every profile with a
+// naive Bayes model and the scores are turned into a confidence for every
+// language. The built in profiles are learned from a sample text of every
+// language of the stopwords package, and they can be extended with more text
+// or new languages.
+package langdetect
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Undetermined is the code returned for a text with no letter of a known
+// language, like a number or a text in another script.
+const Undetermined = "und"
+
+// Result represents a language and the confidence, between 0 and 1, that the
+// text is written in it.
+type Result struct {
+	Lang       string
+	Confidence float64
+}
+
+// String implements the fmt.Stringer interface.
+func (r Result) String() string {
+	return fmt.Sprintf("%s (%.3f)", r.Lang, r.Confidence)
+}
+
+// =============================================================================
+
+// Config defines the settings of a detector.
+type Config struct {
+	// Languages represents the ISO 639-1 codes of the candidate languages.
+	// Restricting the candidates to the languages expected, like the ones of
+	// a product's markets, makes the detection faster and more accurate. An
+	// empty list means every built in language.
+	// Ex: []string{"en", "pt"}
+	Languages []string
+
+	// MaxLength represents the max number of letters of a text looked at,
+	// which is plenty to tell the language of a long document.
+	// Ex: 2048
+	MaxLength int
+}
+
+// NewConfigDefault defines a set of default configuration options.
+func NewConfigDefault() Config {
+	return Config{
+		Languages: nil,
+		MaxLength: 2048,
+	}
+}
+
+// Detector identifies the language of a text among its candidate languages.
+// A detector is safe for concurrent use once it is no longer trained.
+type Detector struct {
+	config   Config
+	langs    []string
+	profiles map[string]*profile
+	vocab    [maxN + 1]map[string]struct{}
+}
+
+// New constructs a detector for the configured languages, with their built in
+// profiles.
+func New(config Config) (*Detector, error) {
+	langs := config.Languages
+	if len(langs) == 0 {
+		langs = Languages()
+	}
+
+	if config.MaxLength <= 0 {
+		config.MaxLength = NewConfigDefault().MaxLength
+	}
+
+	d := Detector{
+		config:   config,
+		profiles: make(map[string]*profile),
+	}
+	for n := range d.vocab {
+		d.vocab[n] = make(map[string]struct{})
+	}
+
+	for _, lang := range langs {
+		code := normalizeLang(lang)
+
+		sample, exists := samples[code]
+		if !exists {
+			return nil, fmt.Errorf("unsupported language %q", lang)
+		}
+
+		d.learn(code, sample)
+	}
+
+	return &d, nil
+}
+
+// Languages returns the codes of the built in languages, sorted. They are the
+// languages of the stopwords package.
+func Languages() []string {
+	langs := make([]string, 0, len(samples))
+	for lang := range samples {
+		langs = append(langs, lang)
+	}
+	slices.Sort(langs)
+
+	return langs
+}
+
+// Languages returns the codes of the candidate languages, sorted.
+func (d *Detector) Languages() []string {
+	return slices.Clone(d.langs)
+}
+
+// Learn extends the profile of the language with a sample text, like a few
+// documents known to be in that language. A language without a profile
+// becomes a candidate, which allows languages without a stop word list.
+func (d *Detector) Learn(lang string, r io.Reader) error {
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+
+	d.learn(normalizeLang(lang), string(text))
+
+	return nil
+}
+
+// Detect returns the most likely language of the text. A text without any
+// letter of the candidate languages is Undetermined with no confidence.
+func (d *Detector) Detect(text string) Result {
+	results := d.DetectAll(text)
+	if len(results) == 0 {
+		return Result{Lang: Undetermined}
+	}
+
+	return results[0]
+}
+
+// DetectAll returns the candidate languages from the most to the least likely
+// for the text, with confidences adding up to 1. It returns no result for a
+// text without any letter of the candidate languages.
+func (d *Detector) DetectAll(text string) []Result {
+	grams := d.grams(text)
+	if len(grams) == 0 {
+		return nil
+	}
+
+	scores := make([]float64, len(d.langs))
+	for i, lang := range d.langs {
+		scores[i] = d.profiles[lang].score(grams, &d.vocab)
+	}
+
+	// Every letter is part of up to maxN n-grams, which are not independent,
+	// so the scores are tempered to keep the confidence of short texts from
+	// being overstated.
+	best := slices.Max(scores)
+
+	var sum float64
+	for i := range scores {
+		scores[i] = math.Exp((scores[i] - best) / maxN)
+		sum += scores[i]
+	}
+
+	results := make([]Result, len(d.langs))
+	for i, lang := range d.langs {
+		results[i] = Result{Lang: lang, Confidence: scores[i] / sum}
+	}
+
+	slices.SortStableFunc(results, func(a, b Result) int {
+		switch {
+		case a.Confidence > b.Confidence:
+			return -1
+		case a.Confidence < b.Confidence:
+			return 1
+		}
+		return 0
+	})
+
+	return results
+}
+
+// =============================================================================
+
+var (
+	defaultOnce     sync.Once
+	defaultDetector *Detector
+)
+
+// Default returns the detector of every built in language with the default
+// configuration.
+func Default() *Detector {
+	defaultOnce.Do(func() {
+		d, err := New(NewConfigDefault())
+		if err != nil {
+			panic(err)
+		}
+		defaultDetector = d
+	})
+
+	return defaultDetector
+}
+
+// Detect returns the most likely language of the text with the default
+// detector.
+func Detect(text string) Result {
+	return Default().Detect(text)
+}
+
+// =============================================================================
+
+// maxN is the size of the longest n-grams of the profiles.
+const maxN = 3
+
+// profile represents the counts of the n-grams of a language by their size.
+type profile struct {
+	counts [maxN + 1]map[string]float64
+	totals [maxN + 1]float64
+}
+
+func (d *Detector) learn(lang string, text string) {
+	p, exists := d.profiles[lang]
+	if !exists {
+		p = &profile{}
+		for n := range p.counts {
+			p.counts[n] = make(map[string]float64)
+		}
+
+		d.profiles[lang] = p
+		d.langs = append(d.langs, lang)
+		slices.Sort(d.langs)
+	}
+
+	forEachGram(words(lang, text), func(n int, gram string) {
+		p.counts[n][gram]++
+		p.totals[n]++
+		d.vocab[n][gram] = struct{}{}
+	})
+}
+
+// score returns the log likelihood of the n-grams, with add one smoothing
+// over the n-grams of every profile.
+func (p *profile) score(grams []gram, vocab *[maxN + 1]map[string]struct{}) float64 {
+	var score float64
+	for _, g := range grams {
+		score += math.Log((p.counts[g.n][g.text] + 1) / (p.totals[g.n] + float64(len(vocab[g.n]))))
+	}
+
+	return score
+}
+
+// gram represents an n-gram of a text.
+type gram struct {
+	n    int
+	text string
+}
+
+// grams returns the n-grams of the text found in at least one profile, as
+// the others say nothing about the language.
+func (d *Detector) grams(text string) []gram {
+	var grams []gram
+	var letters int
+
+	for _, word := range words("", text) {
+		if letters += len([]rune(word)); letters > d.config.MaxLength {
+			break
+		}
+
+		forEachGram([]string{word}, func(n int, g string) {
+			if _, exists := d.vocab[n][g]; exists {
+				grams = append(grams, gram{n: n, text: g})
+			}
+		})
+	}
+
+	return grams
+}
+
+// words returns the lowercase words of the text, made of letters only.
+func words(lang string, text string) []string {
+	if lang == "tr" {
+		text = strings.ToLowerSpecial(unicode.TurkishCase, text)
+	}
+
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r)
+	})
+}
+
+// forEachGram calls fn with every n-gram of the words, which are padded with
+// a space so the n-grams starting and ending a word are told apart.
+func forEachGram(words []string, fn func(n int, gram string)) {
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+
+		for n := 1; n <= maxN; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				if n == 1 && runes[i] == ' ' {
+					continue
+				}
+				fn(n, string(runes[i:i+n]))
+			}
+		}
+	}
+}
+
+// normalizeLang returns the lowercase ISO 639-1 code of the language, without
+// a region like in "pt-BR". The codes of Norwegian Bokmål and Nynorsk become
+// the one of Norwegian, as in the stopwords package.
+func normalizeLang(lang string) string {
+	code := strings.ToLower(lang)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+
+	switch code {
+	case "nb", "nn":
+		code = "no"
+	}
+
+	return code
+}
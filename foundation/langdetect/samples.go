@@ -0,0 +1,220 @@
+package langdetect
+
+// samples maps the ISO 639-1 code of every built in language to the sample
+// text its profile is learned from. The texts are written in a plain register,
+// like reviews and everyday news, about the same topics in every language, so
+// the profiles tell apart the languages and not the topics.
+var samples = map[string]string{
+	"da": `Jeg købte denne telefon for to måneder siden, og jeg er generelt meget
+tilfreds med den. Batteriet holder en hel dag, selv når jeg bruger den meget,
+og skærmen er lys og skarp. Kameraet tager gode billeder om dagen, men om
+aftenen bliver de lidt mørke. Opladeren fulgte ikke med i æsken, hvilket jeg
+synes er lidt skuffende til den pris. Levering var hurtig, og pakken kom
+frem uden skader. Hvis du leder efter en god telefon, som ikke koster alt for
+meget, så kan jeg godt anbefale den. Min kone har også fået en, og hun er lige
+så glad for den. Vi bor på landet, hvor forbindelsen ikke altid er god, men
+telefonen finder hurtigt netværket igen. Kommunen har i år bygget en ny skole
+tæt på byens centrum, og børnene glæder sig til at begynde efter sommerferien.
+Vejret har været koldt og vådt hele ugen, men i weekenden skulle solen endelig
+komme frem. Mange mennesker vælger at cykle til arbejde, fordi det er billigt
+og sundt, og fordi der findes gode cykelstier overalt i landet.`,
+
+	"de": `Ich habe dieses Handy vor zwei Monaten gekauft und bin im Großen und
+Ganzen sehr zufrieden. Der Akku hält einen ganzen Tag, auch wenn ich es viel
+benutze, und der Bildschirm ist hell und scharf. Die Kamera macht tagsüber
+gute Fotos, aber abends werden sie etwas dunkel. Das Ladegerät war nicht im
+Karton, was ich bei diesem Preis ziemlich enttäuschend finde. Die Lieferung
+war schnell und das Paket kam ohne Schäden an. Wenn Sie ein gutes Telefon
+suchen, das nicht zu viel kostet, kann ich es wirklich empfehlen. Meine Frau
+hat sich auch eines gekauft und ist genauso begeistert. Wir wohnen auf dem
+Land, wo die Verbindung nicht immer gut ist, aber das Gerät findet das Netz
+schnell wieder. Die Stadt hat in diesem Jahr eine neue Schule in der Nähe des
+Zentrums gebaut, und die Kinder freuen sich schon auf den Beginn nach den
+Sommerferien. Das Wetter war die ganze Woche kalt und nass, doch am Wochenende
+soll endlich die Sonne scheinen. Viele Menschen fahren mit dem Fahrrad zur
+Arbeit, weil es billig und gesund ist und weil es überall gute Radwege gibt.`,
+
+	"en": `I bought this phone two months ago and overall I am very happy with
+it. The battery lasts a whole day, even when I use it a lot, and the screen is
+bright and sharp. The camera takes good pictures during the day, but in the
+evening they get a little dark. The charger was not in the box, which I think
+is rather disappointing at this price. Delivery was fast and the package
+arrived without any damage. If you are looking for a good phone that does not
+cost too much, I can really recommend it. My wife bought one as well and she
+is just as pleased with it. We live in the country, where the signal is not
+always good, but the phone finds the network again quickly. This year the
+city built a new school close to the centre of town, and the children are
+looking forward to starting after the summer holidays. The weather has been
+cold and wet all week, but the sun should finally come out at the weekend.
+Many people choose to ride a bike to work, because it is cheap and healthy
+and because there are good bike paths everywhere in the country.`,
+
+	"es": `Compré este teléfono hace dos meses y en general estoy muy contento
+con él. La batería dura todo el día, incluso cuando lo uso mucho, y la
+pantalla es brillante y nítida. La cámara saca buenas fotos durante el día,
+pero por la noche salen un poco oscuras. El cargador no venía en la caja, lo
+cual me parece bastante decepcionante por este precio. El envío fue rápido y
+el paquete llegó sin ningún daño. Si buscas un buen teléfono que no cueste
+demasiado, te lo recomiendo de verdad. Mi mujer también se compró uno y está
+igual de contenta. Vivimos en el campo, donde la señal no siempre es buena,
+pero el teléfono vuelve a encontrar la red rápidamente. Este año el
+ayuntamiento construyó una escuela nueva cerca del centro de la ciudad, y los
+niños tienen muchas ganas de empezar después de las vacaciones de verano. El
+tiempo ha sido frío y húmedo toda la semana, pero el fin de semana por fin
+saldrá el sol. Mucha gente elige ir al trabajo en bicicleta, porque es barato
+y sano y porque hay buenos carriles para bicicletas en todo el país.`,
+
+	"fi": `Ostin tämän puhelimen kaksi kuukautta sitten ja olen yleisesti ottaen
+erittäin tyytyväinen siihen. Akku kestää koko päivän, vaikka käyttäisin
+puhelinta paljon, ja näyttö on kirkas ja tarkka. Kamera ottaa hyviä kuvia
+päivällä, mutta illalla niistä tulee hieman tummia. Laturi ei ollut mukana
+pakkauksessa, mikä on mielestäni melko pettymys tähän hintaan. Toimitus oli
+nopea ja paketti saapui ilman vaurioita. Jos etsit hyvää puhelinta, joka ei
+maksa liikaa, voin todella suositella tätä. Vaimoni osti myös samanlaisen ja
+hän on yhtä tyytyväinen. Asumme maalla, missä yhteys ei aina ole hyvä, mutta
+puhelin löytää verkon nopeasti uudelleen. Kaupunki rakensi tänä vuonna uuden
+koulun lähelle keskustaa, ja lapset odottavat innolla koulun alkamista
+kesäloman jälkeen. Sää on ollut kylmä ja sateinen koko viikon, mutta
+viikonloppuna aurinko vihdoin paistaa. Monet ihmiset pyöräilevät töihin,
+koska se on halpaa ja terveellistä ja koska hyviä pyöräteitä on kaikkialla
+maassa. Kirjastossa on paljon uusia kirjoja, joita voi lainata ilmaiseksi.`,
+
+	"fr": `J'ai acheté ce téléphone il y a deux mois et dans l'ensemble j'en suis
+très content. La batterie tient toute la journée, même quand je l'utilise
+beaucoup, et l'écran est lumineux et net. L'appareil photo prend de bonnes
+photos pendant la journée, mais le soir elles sont un peu sombres. Le chargeur
+n'était pas dans la boîte, ce que je trouve assez décevant pour ce prix. La
+livraison a été rapide et le colis est arrivé sans aucun dommage. Si vous
+cherchez un bon téléphone qui ne coûte pas trop cher, je vous le recommande
+vraiment. Ma femme en a aussi acheté un et elle est tout aussi satisfaite.
+Nous habitons à la campagne, où le réseau n'est pas toujours bon, mais le
+téléphone retrouve vite le signal. Cette année, la mairie a construit une
+nouvelle école près du centre de la ville, et les enfants ont hâte de
+commencer après les vacances d'été. Il a fait froid et humide toute la
+semaine, mais le soleil devrait enfin revenir ce week-end. Beaucoup de gens
+choisissent d'aller au travail à vélo, parce que c'est bon marché et bon pour
+la santé et parce qu'il y a de bonnes pistes cyclables partout dans le pays.`,
+
+	"it": `Ho comprato questo telefono due mesi fa e nel complesso ne sono molto
+soddisfatto. La batteria dura tutto il giorno, anche quando lo uso molto, e
+lo schermo è luminoso e nitido. La fotocamera scatta belle foto durante il
+giorno, ma la sera vengono un po' scure. Il caricatore non era nella
+confezione, cosa che trovo piuttosto deludente a questo prezzo. La consegna è
+stata veloce e il pacco è arrivato senza alcun danno. Se cercate un buon
+telefono che non costi troppo, ve lo consiglio davvero. Anche mia moglie ne
+ha comprato uno ed è altrettanto contenta. Abitiamo in campagna, dove il
+segnale non è sempre buono, ma il telefono ritrova subito la rete. Quest'anno
+il comune ha costruito una nuova scuola vicino al centro della città, e i
+bambini non vedono l'ora di cominciare dopo le vacanze estive. Il tempo è
+stato freddo e umido per tutta la settimana, ma nel fine settimana dovrebbe
+finalmente tornare il sole. Molte persone scelgono di andare al lavoro in
+bicicletta, perché è economico e fa bene alla salute e perché ci sono buone
+piste ciclabili in tutto il paese.`,
+
+	"nl": `Ik heb deze telefoon twee maanden geleden gekocht en over het algemeen
+ben ik er erg tevreden mee. De batterij gaat een hele dag mee, ook als ik hem
+veel gebruik, en het scherm is helder en scherp. De camera maakt overdag
+goede foto's, maar 's avonds worden ze een beetje donker. De oplader zat niet
+in de doos, wat ik voor deze prijs nogal teleurstellend vind. De levering was
+snel en het pakket kwam zonder schade aan. Als je een goede telefoon zoekt
+die niet te veel kost, dan kan ik hem echt aanraden. Mijn vrouw heeft er ook
+een gekocht en zij is net zo blij. Wij wonen op het platteland, waar de
+verbinding niet altijd goed is, maar de telefoon vindt het netwerk snel
+terug. De gemeente heeft dit jaar een nieuwe school gebouwd vlak bij het
+centrum van de stad, en de kinderen kijken ernaar uit om na de
+zomervakantie te beginnen. Het weer was de hele week koud en nat, maar in het
+weekend zou de zon eindelijk gaan schijnen. Veel mensen kiezen ervoor om met
+de fiets naar hun werk te gaan, omdat het goedkoop en gezond is en omdat er
+overal in het land goede fietspaden zijn.`,
+
+	"no": `Jeg kjøpte denne telefonen for to måneder siden, og jeg er stort sett
+veldig fornøyd med den. Batteriet varer en hel dag, selv når jeg bruker den
+mye, og skjermen er lys og skarp. Kameraet tar gode bilder på dagtid, men om
+kvelden blir de litt mørke. Laderen fulgte ikke med i esken, noe jeg synes er
+ganske skuffende til denne prisen. Leveringen gikk raskt, og pakken kom fram
+uten skader. Hvis du ser etter en god telefon som ikke koster for mye, kan jeg
+virkelig anbefale den. Kona mi kjøpte også en, og hun er like fornøyd. Vi bor
+på landet, hvor dekningen ikke alltid er god, men telefonen finner nettet
+raskt igjen. I år bygde kommunen en ny skole like ved sentrum, og barna
+gleder seg til å begynne etter sommerferien. Været har vært kaldt og vått
+hele uka, men i helgen skal sola endelig komme fram. Mange velger å sykle
+til jobben, fordi det er billig og sunt, og fordi det finnes gode sykkelveier
+over hele landet. Jeg har ikke hatt noen problemer med telefonen ennå, og
+det er bare å håpe at den holder seg slik i mange år.`,
+
+	"pl": `Kupiłem ten telefon dwa miesiące temu i ogólnie jestem z niego bardzo
+zadowolony. Bateria wytrzymuje cały dzień, nawet kiedy dużo go używam, a
+ekran jest jasny i wyraźny. Aparat robi dobre zdjęcia w ciągu dnia, ale
+wieczorem wychodzą trochę ciemne. Ładowarki nie było w pudełku, co uważam za
+dość rozczarowujące przy tej cenie. Dostawa była szybka i paczka przyszła bez
+żadnych uszkodzeń. Jeśli szukasz dobrego telefonu, który nie kosztuje zbyt
+dużo, naprawdę mogę go polecić. Moja żona też kupiła taki sam i jest równie
+zadowolona. Mieszkamy na wsi, gdzie zasięg nie zawsze jest dobry, ale telefon
+szybko znajduje sieć. W tym roku miasto zbudowało nową szkołę blisko centrum,
+a dzieci nie mogą się doczekać, kiedy zaczną naukę po wakacjach. Przez cały
+tydzień było zimno i mokro, ale w weekend słońce ma w końcu wyjść zza chmur.
+Wielu ludzi wybiera dojazd do pracy rowerem, ponieważ jest to tanie i zdrowe,
+a w całym kraju są dobre ścieżki rowerowe. Nie miałem jeszcze żadnych
+problemów z tym telefonem i mam nadzieję, że tak zostanie.`,
+
+	"pt": `Comprei este telefone há dois meses e, de modo geral, estou muito
+satisfeito com ele. A bateria dura o dia inteiro, mesmo quando uso bastante,
+e a tela é brilhante e nítida. A câmera tira boas fotos durante o dia, mas à
+noite elas ficam um pouco escuras. O carregador não veio na caixa, o que acho
+bastante decepcionante por esse preço. A entrega foi rápida e o pacote chegou
+sem nenhum dano. Se você está procurando um bom telefone que não custe muito,
+eu realmente recomendo. Minha mulher também comprou um e está tão contente
+quanto eu. Moramos no interior, onde o sinal nem sempre é bom, mas o telefone
+encontra a rede de novo rapidamente. Este ano a prefeitura construiu uma
+escola nova perto do centro da cidade, e as crianças estão ansiosas para
+começar depois das férias de verão. O tempo esteve frio e úmido a semana
+toda, mas no fim de semana o sol finalmente deve aparecer. Muitas pessoas
+escolhem ir ao trabalho de bicicleta, porque é barato e saudável e porque há
+boas ciclovias em todo o país. Não tive nenhum problema com o aparelho até
+agora e espero que continue assim por muitos anos.`,
+
+	"ru": `Я купил этот телефон два месяца назад и в целом очень доволен им.
+Батарея держит целый день, даже когда я много им пользуюсь, а экран яркий и
+чёткий. Камера делает хорошие снимки днём, но вечером они получаются немного
+тёмными. Зарядного устройства не было в коробке, что, по-моему, довольно
+обидно за такую цену. Доставка была быстрой, и посылка пришла без
+повреждений. Если вы ищете хороший телефон, который стоит не слишком дорого,
+я действительно могу его порекомендовать. Моя жена тоже купила такой же и
+довольна не меньше. Мы живём за городом, где связь не всегда хорошая, но
+телефон быстро находит сеть снова. В этом году город построил новую школу
+недалеко от центра, и дети с нетерпением ждут начала учёбы после летних
+каникул. Всю неделю было холодно и сыро, но в выходные наконец должно
+выглянуть солнце. Многие люди ездят на работу на велосипеде, потому что это
+дёшево и полезно для здоровья и потому что по всей стране есть хорошие
+велосипедные дорожки.`,
+
+	"sv": `Jag köpte den här telefonen för två månader sedan och överlag är jag
+mycket nöjd med den. Batteriet räcker en hel dag, även när jag använder den
+mycket, och skärmen är ljus och skarp. Kameran tar bra bilder på dagen, men
+på kvällen blir de lite mörka. Laddaren följde inte med i kartongen, vilket
+jag tycker är ganska tråkigt för det priset. Leveransen gick snabbt och
+paketet kom fram utan några skador. Om du letar efter en bra telefon som inte
+kostar för mycket kan jag verkligen rekommendera den. Min fru köpte också en
+och hon är lika nöjd. Vi bor på landet, där täckningen inte alltid är bra,
+men telefonen hittar snabbt nätet igen. I år byggde kommunen en ny skola nära
+centrum, och barnen längtar efter att börja efter sommarlovet. Vädret har
+varit kallt och blött hela veckan, men i helgen ska solen äntligen titta
+fram. Många väljer att cykla till jobbet, eftersom det är billigt och
+nyttigt och eftersom det finns bra cykelvägar i hela landet. Jag har inte
+haft några problem med telefonen än och hoppas att det fortsätter så.`,
+
+	"tr": `Bu telefonu iki ay önce aldım ve genel olarak çok memnunum. Pil, çok
+kullandığım zamanlarda bile bütün gün dayanıyor ve ekran parlak ve net.
+Kamera gündüz güzel fotoğraflar çekiyor, ama akşamları fotoğraflar biraz
+karanlık çıkıyor. Şarj cihazı kutunun içinde yoktu, bu fiyata göre bunu
+oldukça hayal kırıklığı olarak görüyorum. Teslimat hızlıydı ve paket hiçbir
+hasar olmadan geldi. Çok pahalı olmayan iyi bir telefon arıyorsanız, bunu
+gerçekten tavsiye ederim. Eşim de bir tane aldı ve o da en az benim kadar
+memnun. Köyde yaşıyoruz, orada sinyal her zaman iyi değil, ama telefon ağı
+hemen tekrar buluyor. Belediye bu yıl şehir merkezine yakın yeni bir okul
+yaptı ve çocuklar yaz tatilinden sonra okula başlamak için sabırsızlanıyor.
+Hava bütün hafta soğuk ve yağışlıydı, ama hafta sonu nihayet güneş açacak.
+Birçok insan işe bisikletle gitmeyi tercih ediyor, çünkü bu hem ucuz hem de
+sağlıklı ve ülkenin her yerinde güzel bisiklet yolları var. Şimdiye kadar
+telefonla hiçbir sorun yaşamadım ve umarım böyle devam eder.`,
+}
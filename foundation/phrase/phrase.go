@@ -0,0 +1,312 @@
+// Package phrase provides support for detecting phrases (collocations) in a
+// corpus and joining their words into a single token, so "battery life"
+// becomes "battery_life". The scoring follows the word2phrase tool from the
+// original word2vec distribution:
+// https://github.com/tmikolov/word2vec/blob/master/word2phrase.c
+//
+// The corpus is expected to be cleaned text with one sentence per line and
+// words separated by white space. When the rewritten corpus is used to train
+// a word2vec model, the Delimiter must be removed from the word2vec Tokenizer.
+package phrase
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Config represents the settings for learning phrases.
+type Config struct {
+	// MinCount represents when words and bigrams should be discarded that
+	// appear less than <int> times.
+	// Ex: 5
+	MinCount int
+
+	// Threshold represents the score a bigram needs to become a phrase.
+	// Higher values mean fewer phrases.
+	// Ex: 100
+	Threshold float64
+
+	// Passes represents the number of learning passes over the corpus. Every
+	// pass can join phrases found by the previous ones into longer phrases.
+	// Ex: 2
+	Passes int
+
+	// Delimiter represents the string used to join the words of a phrase.
+	// Ex: "_"
+	Delimiter string
+}
+
+// NewConfigDefault defines a set of default configuration options.
+func NewConfigDefault() Config {
+	return Config{
+		MinCount:  5,
+		Threshold: 100,
+		Passes:    2,
+		Delimiter: "_",
+	}
+}
+
+// Phrase represents a learned phrase.
+type Phrase struct {
+	Left  string
+	Right string
+	Score float64
+	Pass  int
+}
+
+// =============================================================================
+
+type bigram struct {
+	left  string
+	right string
+}
+
+// Table represents the phrases learned from a corpus. Phrases are applied in
+// the order of the passes that learned them.
+type Table struct {
+	delimiter string
+	passes    []map[bigram]float64
+}
+
+// Learn reads the corpus once per pass and learns the bigrams that score over
+// the threshold. Every pass reads the corpus rewritten with the phrases found
+// so far.
+func Learn(corpus io.ReadSeeker, config Config) (*Table, error) {
+	if config.Passes <= 0 {
+		return nil, errors.New("passes must be greater than zero")
+	}
+
+	t := Table{
+		delimiter: config.Delimiter,
+	}
+
+	for pass := 0; pass < config.Passes; pass++ {
+		if _, err := corpus.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("seek: %w", err)
+		}
+
+		phrases, err := t.learn(corpus, config)
+		if err != nil {
+			return nil, fmt.Errorf("pass %d: %w", pass+1, err)
+		}
+
+		if len(phrases) == 0 {
+			break
+		}
+
+		t.passes = append(t.passes, phrases)
+	}
+
+	return &t, nil
+}
+
+func (t *Table) learn(r io.Reader, config Config) (map[bigram]float64, error) {
+	unigrams := make(map[string]int)
+	bigrams := make(map[bigram]int)
+	var trainWords int
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+
+	for scanner.Scan() {
+		tokens := t.Apply(strings.Fields(scanner.Text()))
+
+		for i, token := range tokens {
+			unigrams[token]++
+			trainWords++
+
+			if i > 0 {
+				bigrams[bigram{tokens[i-1], token}]++
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read corpus: %w", err)
+	}
+
+	phrases := make(map[bigram]float64)
+	minCount := float64(config.MinCount)
+
+	for bg, count := range bigrams {
+		pa := unigrams[bg.left]
+		pb := unigrams[bg.right]
+		if pa < config.MinCount || pb < config.MinCount {
+			continue
+		}
+
+		score := (float64(count) - minCount) / float64(pa) / float64(pb) * float64(trainWords)
+		if score > config.Threshold {
+			phrases[bg] = score
+		}
+	}
+
+	return phrases, nil
+}
+
+// Phrases returns the learned phrases ordered by pass and descending score.
+func (t *Table) Phrases() []Phrase {
+	var phrases []Phrase
+	for pass, bigrams := range t.passes {
+		for bg, score := range bigrams {
+			phrases = append(phrases, Phrase{
+				Left:  bg.left,
+				Right: bg.right,
+				Score: score,
+				Pass:  pass + 1,
+			})
+		}
+	}
+
+	sort.Slice(phrases, func(i, j int) bool {
+		if phrases[i].Pass != phrases[j].Pass {
+			return phrases[i].Pass < phrases[j].Pass
+		}
+		if phrases[i].Score != phrases[j].Score {
+			return phrases[i].Score > phrases[j].Score
+		}
+		return phrases[i].Left+" "+phrases[i].Right < phrases[j].Left+" "+phrases[j].Right
+	})
+
+	return phrases
+}
+
+// Apply joins the tokens that form a phrase, pass by pass, from left to
+// right.
+func (t *Table) Apply(tokens []string) []string {
+	for _, bigrams := range t.passes {
+		if len(tokens) < 2 {
+			break
+		}
+
+		out := make([]string, 0, len(tokens))
+		for i := 0; i < len(tokens); i++ {
+			if i+1 < len(tokens) {
+				if _, exists := bigrams[bigram{tokens[i], tokens[i+1]}]; exists {
+					out = append(out, tokens[i]+t.delimiter+tokens[i+1])
+					i++
+					continue
+				}
+			}
+			out = append(out, tokens[i])
+		}
+
+		tokens = out
+	}
+
+	return tokens
+}
+
+// Rewrite joins the phrases in the text, so query text matches the rewritten
+// corpus. The words in the result are separated by a single space.
+func (t *Table) Rewrite(text string) string {
+	return strings.Join(t.Apply(strings.Fields(text)), " ")
+}
+
+// RewriteCorpus reads the corpus line by line and writes every line with its
+// phrases joined.
+func (t *Table) RewriteCorpus(r io.Reader, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+
+	for scanner.Scan() {
+		bw.WriteString(t.Rewrite(scanner.Text()))
+		bw.WriteByte('\n')
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read corpus: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("write corpus: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// Save writes the phrase table to a TSV file. The first line holds the
+// delimiter, every other line a phrase with its pass, words and score.
+func (t *Table) Save(fileTable string) error {
+	f, err := os.Create(fileTable)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+
+	fmt.Fprintf(w, "delimiter\t%s\n", t.delimiter)
+	for _, p := range t.Phrases() {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%g\n", p.Pass, p.Left, p.Right, p.Score)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads a phrase table written by Save.
+func Load(fileTable string) (*Table, error) {
+	f, err := os.Open(fileTable)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	if !scanner.Scan() {
+		return nil, errors.New("missing delimiter")
+	}
+
+	name, delimiter, found := strings.Cut(scanner.Text(), "\t")
+	if !found || name != "delimiter" {
+		return nil, errors.New("missing delimiter")
+	}
+
+	t := Table{
+		delimiter: delimiter,
+	}
+
+	for line := 2; scanner.Scan(); line++ {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("line %d: expected 4 fields, got %d", line, len(fields))
+		}
+
+		pass, err := strconv.Atoi(fields[0])
+		if err != nil || pass < 1 {
+			return nil, fmt.Errorf("line %d: invalid pass %q", line, fields[0])
+		}
+
+		score, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		for len(t.passes) < pass {
+			t.passes = append(t.passes, make(map[bigram]float64))
+		}
+
+		t.passes[pass-1][bigram{fields[1], fields[2]}] = score
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	return &t, nil
+}
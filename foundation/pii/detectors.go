@@ -0,0 +1,315 @@
+package pii
+
+import (
+	"net"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ardanlabs/ai-training/foundation/stopwords"
+)
+
+// detector finds the entities of a kind in a text.
+type detector struct {
+	kind Kind
+	find func(r *Redactor, text string) []Entity
+}
+
+// detectors lists the detector of every kind in the order of Kinds.
+var detectors = []detector{
+	{Email, findEmails},
+	{CreditCard, findCreditCards},
+	{SSN, findSSNs},
+	{IPAddress, findIPAddresses},
+	{Phone, findPhones},
+	{Address, findAddresses},
+	{Name, findNames},
+}
+
+// matches returns the spans of the pattern in the text that the function
+// accepts, or every span if the function is nil.
+func matches(pattern *regexp.Regexp, text string, accept func(start int, end int) bool) []Entity {
+	var entities []Entity
+	for _, m := range pattern.FindAllStringIndex(text, -1) {
+		if accept == nil || accept(m[0], m[1]) {
+			entities = append(entities, Entity{Start: m[0], End: m[1]})
+		}
+	}
+
+	return entities
+}
+
+// isolated reports whether the span is not part of a longer run of letters
+// and digits, since the patterns of numbers can't use \b around separators.
+func isolated(text string, start int, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return false
+	}
+
+	if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return false
+	}
+
+	return true
+}
+
+// digits returns the digits of the text.
+func digits(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, text)
+}
+
+// =============================================================================
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+
+func findEmails(_ *Redactor, text string) []Entity {
+	return matches(emailPattern, text, nil)
+}
+
+// =============================================================================
+
+var creditCardPattern = regexp.MustCompile(`\d(?:[ -]?\d){12,18}`)
+
+// findCreditCards finds the numbers of 13 to 19 digits, optionally grouped
+// with spaces or dashes, that start like a card of a known network and pass
+// the Luhn checksum.
+func findCreditCards(_ *Redactor, text string) []Entity {
+	return matches(creditCardPattern, text, func(start int, end int) bool {
+		if !isolated(text, start, end) {
+			return false
+		}
+
+		number := digits(text[start:end])
+		if !strings.ContainsAny(number[:1], "23456") {
+			return false
+		}
+
+		return luhn(number)
+	})
+}
+
+// luhn reports whether the digits pass the Luhn checksum.
+func luhn(number string) bool {
+	var sum int
+	double := false
+
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// =============================================================================
+
+var ssnPattern = regexp.MustCompile(`\b(\d{3})-(\d{2})-(\d{4})\b`)
+
+// findSSNs finds the US social security numbers, leaving out the ones that
+// are never issued, like an area of 000, 666 or 9xx.
+func findSSNs(_ *Redactor, text string) []Entity {
+	var entities []Entity
+	for _, m := range ssnPattern.FindAllStringSubmatchIndex(text, -1) {
+		area, group, serial := text[m[2]:m[3]], text[m[4]:m[5]], text[m[6]:m[7]]
+
+		switch {
+		case area == "000", area == "666", area[0] == '9':
+		case group == "00", serial == "0000":
+		default:
+			entities = append(entities, Entity{Start: m[0], End: m[1]})
+		}
+	}
+
+	return entities
+}
+
+// =============================================================================
+
+var ipPattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+
+// findIPAddresses finds the IPv4 addresses, except for the unspecified and
+// loopback addresses, which are the same on every machine.
+func findIPAddresses(_ *Redactor, text string) []Entity {
+	return matches(ipPattern, text, func(start int, end int) bool {
+		if !isolated(text, start, end) {
+			return false
+		}
+
+		ip := net.ParseIP(text[start:end])
+
+		return ip != nil && !ip.IsUnspecified() && !ip.IsLoopback()
+	})
+}
+
+// =============================================================================
+
+var (
+	phonePattern = regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{1,4}\)[ .-]?)?\d{2,12}(?:[ .-]\d{2,5}){0,4}`)
+	datePattern  = regexp.MustCompile(`^(?:\d{4}[-./]\d{1,2}[-./]\d{1,2}|\d{1,2}[-./]\d{1,2}[-./]\d{2,4})$`)
+	localPattern = regexp.MustCompile(`^\d{3}[ .-]\d{4}$`)
+	pairsPattern = regexp.MustCompile(`^0\d(?:[ .-]\d{2})+$`)
+	cuePattern   = regexp.MustCompile(`(?i)\b(?:phone|tel|telephone|call|cell|mobile|fax|text|contact|whatsapp|telefone|tel[eé]fono|celular|m[oó]vil)\b[^\n\d]{0,12}$`)
+)
+
+// findPhones finds the phone numbers of 7 to 15 digits. Numbers without a
+// country code or area code in parentheses have to be grouped like a phone
+// number with a single kind of separator, to leave out other numbers like
+// dates, amounts, timestamps and lists of numbers. A number of 10 digits
+// written without separators, like 4155550123, is only a phone number after
+// a word like "phone" or "call", since ids and sizes look the same.
+func findPhones(_ *Redactor, text string) []Entity {
+	return matches(phonePattern, text, func(start int, end int) bool {
+		if !isolated(text, start, end) {
+			return false
+		}
+
+		phone := text[start:end]
+
+		n := len(digits(phone))
+		if n < 7 || n > 15 || datePattern.MatchString(phone) {
+			return false
+		}
+
+		if strings.ContainsAny(phone, "+(") {
+			return true
+		}
+
+		var separators int
+		for _, sep := range []string{" ", ".", "-"} {
+			if strings.Contains(phone, sep) {
+				separators++
+			}
+		}
+
+		groups := strings.FieldsFunc(phone, func(r rune) bool {
+			return r == ' ' || r == '.' || r == '-'
+		})
+
+		switch {
+		case separators > 1:
+			return false
+		case len(groups) == 1:
+			return n == 10 && phone[0] >= '2' && cuePattern.MatchString(text[max(0, start-32):start])
+		case len(groups) == 2:
+			return localPattern.MatchString(phone)
+		case len(groups[1]) == 2:
+			return pairsPattern.MatchString(phone)
+		}
+
+		return true
+	})
+}
+
+// =============================================================================
+
+var addressPatterns = []*regexp.Regexp{
+
+	// A number, the name of the street and its type, like "221 Baker
+	// Street", optionally followed by a unit and a city, state and ZIP code.
+	regexp.MustCompile(`\b\d{1,6}[A-Za-z]?[ \t]+(?:[A-Z][\p{L}'.-]*[ \t]+){1,4}(?i:` + streetTypes + `)\b\.?` +
+		`(?:,?[ \t]+(?:(?i:apt|apartment|suite|ste|unit|floor|fl)\.?|#)[ \t]*[A-Za-z0-9-]+)?` +
+		`(?:,[ \t]*(?:[A-Z][\p{L}'.-]*[ \t]+){0,2}[A-Z][\p{L}'.-]*,?[ \t]+[A-Z]{2}[ \t]+\d{5}(?:-\d{4})?)?`),
+
+	// A post office box, like "P.O. Box 1234".
+	regexp.MustCompile(`\b(?i:p\.?[ \t]?o\.?[ \t]+box|post[ \t]+office[ \t]+box)[ \t]+\d+`),
+
+	// The type of the street before its name and number, as in Portuguese
+	// and Spanish, like "Rua Augusta, 1500" or "Calle Mayor 12".
+	regexp.MustCompile(`\b(?:` + streetTypesBefore + `)[ \t]+(?:(?:d[aeo]s?|del?|la)[ \t]+)?(?:[\p{Lu}][\p{L}'.-]*[ \t]*){1,4},?[ \t]*(?:n[ºo°.][ \t]*)?\d{1,5}`),
+}
+
+func findAddresses(_ *Redactor, text string) []Entity {
+	var entities []Entity
+	for _, pattern := range addressPatterns {
+		entities = append(entities, matches(pattern, text, nil)...)
+	}
+
+	return entities
+}
+
+// =============================================================================
+
+var (
+	honorificPattern = regexp.MustCompile(`\b(?:` + honorifics + `)\.?[ \t]+[\p{Lu}][\p{L}'-]+(?:[ \t]+[\p{Lu}][\p{L}'-]+)?`)
+	capitalPattern   = regexp.MustCompile(`[\p{Lu}][\p{Ll}'-]+(?:[ \t]+[\p{Lu}][\p{Ll}'-]+)*`)
+	wordPattern      = regexp.MustCompile(`[\p{Lu}][\p{Ll}'-]+`)
+)
+
+// nameStopWords holds the English, Portuguese and Spanish stop words, which
+// are not names when they follow an honorific.
+var nameStopWords = func() map[string]struct{} {
+	words := make(map[string]struct{})
+	for _, lang := range []string{"en", "pt", "es"} {
+		list, _ := stopwords.Words(lang)
+		for _, word := range list {
+			words[word] = struct{}{}
+		}
+	}
+
+	return words
+}()
+
+// findNames finds the names following an honorific, like "Dr. Smith", and
+// the names starting with a first name of the dictionary, like "John" or
+// "Mary Ann Jones", in a run of capitalized words. The word after an
+// honorific can't be a stop word, which leaves out phrases like "Ms. This".
+// First names that are also common words, like "Mark" or "Grace", have to be
+// followed by a known last name, which leaves out phrases like "Mark Assist"
+// or "Mark Setup".
+func findNames(r *Redactor, text string) []Entity {
+	entities := matches(honorificPattern, text, func(start int, end int) bool {
+		words := strings.Fields(text[start:end])
+		name := strings.ToLower(words[1])
+		if _, exists := r.names[name]; exists {
+			return true
+		}
+
+		_, exists := nameStopWords[name]
+		return !exists
+	})
+
+	for _, run := range capitalPattern.FindAllStringIndex(text, -1) {
+		if !isolated(text, run[0], run[1]) {
+			continue
+		}
+
+		words := wordPattern.FindAllStringIndex(text[run[0]:run[1]], -1)
+
+		for i, w := range words {
+			first := strings.ToLower(text[run[0]+w[0] : run[0]+w[1]])
+			if _, exists := r.names[first]; !exists {
+				continue
+			}
+
+			// The name takes up to two more words of the run.
+			last := min(i+2, len(words)-1)
+			if _, exists := ambiguousNames[first]; exists {
+				if last == i {
+					continue
+				}
+
+				next := strings.ToLower(text[run[0]+words[i+1][0] : run[0]+words[i+1][1]])
+				if _, exists := r.lastNames[next]; !exists {
+					continue
+				}
+			}
+
+			entities = append(entities, Entity{Start: run[0] + w[0], End: run[0] + words[last][1]})
+			break
+		}
+	}
+
+	return entities
+}
@@ -0,0 +1,82 @@
+package pii
+
+// streetTypes lists the English street types and their abbreviations, as a
+// regular expression alternation, for the addresses with the type after the
+// name of the street.
+const streetTypes = `street|st|avenue|ave|road|rd|boulevard|blvd|lane|ln|drive|dr|` +
+	`court|ct|way|place|pl|terrace|ter|circle|cir|parkway|pkwy|highway|hwy|` +
+	`square|sq|trail|trl|crescent|close|row|alley|plaza`
+
+// streetTypesBefore lists the Portuguese and Spanish street types, which
+// come before the name of the street.
+const streetTypesBefore = `Rua|R\.|Avenida|Av\.|Alameda|Travessa|Praça|Largo|Rodovia|` +
+	`Calle|C/|Carrera|Paseo|Plaza|Camino`
+
+// honorifics lists the titles a name follows.
+const honorifics = `Mr|Mrs|Ms|Miss|Mx|Dr|Prof|Sir|Dame|Sr|Sra|Srta|Dra|Dona`
+
+// firstNames lists common first names of English, Portuguese and Spanish
+// speakers, in lowercase.
+const firstNames = `
+aaron adam adrian agnes aidan alan albert alberto alejandro alex alexander
+alexandra alice alicia alison amanda amber amy ana andrea andrew angela anna
+anne anthony antonio april arthur ashley audrey austin barbara benjamin
+bernard beth betty beverly bill billy bob bobby bonnie brad bradley brandon
+brenda brian brittany bruce bruno bryan caio camila carl carla carlos carmen
+carol caroline carolyn catherine charles charlotte cheryl chloe chris
+christian christina christine christopher cindy claire clara claudia colin
+connie craig cristina crystal cynthia daniel daniela danielle david dean
+debbie deborah debra denise dennis derek diana diane diego donald donna doris
+dorothy douglas dylan eduardo edward elaine eleanor elena elizabeth ellen
+emily emma eric erica erin ethan eugene evelyn felipe fernanda fernando
+frances francisco frank gabriel gabriela gary george gerald gina gloria
+gordon grace gregory guilherme gustavo hannah harold harry heather helen
+henry holly howard ian isabel isabella jack jacob jacqueline james jamie jane
+janet janice jason javier jean jeffrey jennifer jeremy jerry jesse jessica
+jill joan joanne joe joel john johnny jonathan jordan jorge jose joseph
+joshua joyce juan judith judy julia julian julie justin karen katherine
+kathleen kathryn kathy katie keith kelly kenneth kevin kim kimberly kyle
+larissa laura lauren lawrence leonardo leslie linda lisa lois lori louis
+lucas lucia luis luiz luke madison manuel marcelo marcos margaret maria
+marie marilyn mario mark martha martin mary matheus matthew megan melissa
+michael michelle miguel mildred nancy natalia nathan nicholas nicole noah
+norma olivia oscar pablo pamela patricia patrick paul paula pedro peter
+philip phillip rachel rafael ralph randy raquel raymond rebecca renata
+ricardo richard rita robert roberto robin rodrigo roger ronald rose roy
+russell ruth ryan samantha samuel sandra sara sarah scott sean sergio
+sharon shirley sophia stephanie stephen steve steven susan tammy teresa
+terry theresa thiago thomas timothy tina todd tony tyler valerie vanessa
+victor victoria vincent virginia walter wayne william willie zachary
+`
+
+// lastNames lists common last names of English, Portuguese and Spanish
+// speakers, in lowercase. They confirm the first names that are also common
+// words.
+const lastNames = `
+adams allen alvarez anderson almeida alves araujo bailey baker barbosa barnes
+bell bennett brooks brown bryant butler campbell cardoso carter carvalho
+castillo castro chavez clark collins cook cooper costa cox cruz davies davis
+diaz dias edwards evans ferreira fernandes fernandez fisher flores foster
+garcia gomes gomez gonzalez gordon graham gray green griffin gutierrez hall
+hamilton harris hayes henderson hernandez hill howard hughes jackson james
+jenkins jimenez johnson jones jordan kelly kennedy king lee lewis lima long
+lopes lopez marques marshall martin martinez martins mason mcdonald medina
+melo mendes mendez miller mitchell moore morales moreno morgan morris murphy
+murray myers nelson nunes oliveira ortiz owens parker patel perez pereira
+perry peterson phillips pinto powell price ramirez ramos reed reis reyes
+ribeiro richardson rivera roberts robinson rocha rodrigues rodriguez rogers
+romero ross ruiz russell sanchez sanders santos scott silva simmons smith
+soares sousa souza stewart sullivan taylor teixeira thomas thompson torres
+turner vargas vieira walker ward washington watson white williams wilson
+wood wright young
+`
+
+// ambiguousNames lists the first names that are also common words or places,
+// which are only names when followed by a known last name.
+var ambiguousNames = map[string]struct{}{
+	"april": {}, "austin": {}, "bill": {}, "crystal": {}, "dean": {},
+	"grace": {}, "holly": {}, "jack": {}, "jordan": {}, "joy": {},
+	"mark": {}, "may": {}, "rose": {}, "victoria": {}, "virginia": {},
+	"will": {}, "frank": {}, "amber": {}, "robin": {}, "summer": {},
+	"dawn": {}, "faith": {}, "hope": {}, "june": {}, "florence": {},
+}
@@ -0,0 +1,411 @@
+// Package pii provides support for finding and redacting the personal data of
+// a text, like names, emails, phone numbers, credit card numbers and
+// addresses, before it is embedded or stored.
+//
+// Entities are found with regular expressions, checked when possible with
+// their checksum or validity rules, like the Luhn checksum of credit card
+// numbers, and with dictionaries, like common first names and street types.
+// Found entities are replaced by a placeholder of their kind, like [EMAIL], or
+// by a reversible token, like pii_email_dpckjmblhnaeigpf, that a Redactor
+// can map back to the original value.
+package pii
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Kind represents the kind of a personal data entity.
+type Kind string
+
+// Set of known kinds of entities.
+const (
+	Email      Kind = "EMAIL"
+	Phone      Kind = "PHONE"
+	CreditCard Kind = "CREDIT_CARD"
+	SSN        Kind = "SSN"
+	IPAddress  Kind = "IP_ADDRESS"
+	Name       Kind = "NAME"
+	Address    Kind = "ADDRESS"
+)
+
+// Kinds returns every known kind of entity, in the order they are detected.
+// When two entities overlap, the one starting first is kept, then the
+// longest, and only then the one of the kind listed first.
+func Kinds() []Kind {
+	return []Kind{Email, CreditCard, SSN, IPAddress, Phone, Address, Name}
+}
+
+// Entity represents a personal data entity found in a text.
+type Entity struct {
+	Kind Kind
+	Text string
+
+	// Start and End are the byte offsets of the entity in the text.
+	Start int
+	End   int
+}
+
+// Redaction represents the replacement of an entity. It records where the
+// entity was and what replaced it, but never the original value, so the
+// redactions can be logged safely.
+type Redaction struct {
+	Kind        Kind   `json:"kind"`
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Replacement string `json:"replacement"`
+}
+
+// =============================================================================
+
+// Config defines the settings of a redactor.
+type Config struct {
+	// Kinds represents the kinds of entities to redact. An empty list means
+	// every kind.
+	// Ex: []pii.Kind{pii.Email, pii.Phone}
+	Kinds []Kind
+
+	// Reversible represents replacing the entities by tokens, like
+	// pii_email_dpckjmblhnaeigpf, that the redactor can restore, instead of
+	// placeholders, like [EMAIL]. The same value always gets the same token,
+	// so the redacted documents still tell apart different people. Tokens
+	// are made of lowercase letters and underscores only, so they are still
+	// restored after the text is lowercased or its punctuation, numbers and
+	// stop words are dropped.
+	// Ex: false
+	Reversible bool
+
+	// Secret represents the key the tokens are derived from. Tokens are the
+	// same across runs only with the same secret. A random secret is used
+	// when empty.
+	// Ex: []byte(os.Getenv("PII_SECRET"))
+	Secret []byte
+
+	// Log represents where the redactions are written, as one JSON object
+	// per line. The original values are never written. Nil means no log.
+	// Ex: os.Stderr
+	Log io.Writer
+}
+
+// NewConfigDefault defines a set of default configuration options.
+func NewConfigDefault() Config {
+	return Config{
+		Kinds:      nil,
+		Reversible: false,
+		Secret:     nil,
+		Log:        nil,
+	}
+}
+
+// Redactor finds and replaces the personal data of texts. It is safe for
+// concurrent use once its names are added.
+type Redactor struct {
+	config    Config
+	detectors []detector
+	names     map[string]struct{}
+	lastNames map[string]struct{}
+
+	mu     sync.Mutex
+	vault  map[string]string
+	counts map[Kind]int
+}
+
+// New constructs a redactor with the specified configuration.
+func New(config Config) (*Redactor, error) {
+	kinds := config.Kinds
+	if len(kinds) == 0 {
+		kinds = Kinds()
+	}
+
+	if len(config.Secret) == 0 {
+		config.Secret = make([]byte, 32)
+		if _, err := rand.Read(config.Secret); err != nil {
+			return nil, fmt.Errorf("secret: %w", err)
+		}
+	}
+
+	r := Redactor{
+		config:    config,
+		names:     make(map[string]struct{}),
+		lastNames: make(map[string]struct{}),
+		vault:     make(map[string]string),
+		counts:    make(map[Kind]int),
+	}
+
+	for _, name := range strings.Fields(firstNames) {
+		r.names[name] = struct{}{}
+	}
+
+	for _, name := range strings.Fields(lastNames) {
+		r.lastNames[name] = struct{}{}
+	}
+
+	for _, kind := range kinds {
+		if !slices.Contains(Kinds(), kind) {
+			return nil, fmt.Errorf("unknown kind %q", kind)
+		}
+	}
+
+	// The detectors run in the order of Kinds, whatever the order of the
+	// configuration.
+	for _, d := range detectors {
+		if slices.Contains(kinds, d.kind) {
+			r.detectors = append(r.detectors, d)
+		}
+	}
+
+	return &r, nil
+}
+
+// AddNames adds first names to the dictionary names are found with, like the
+// names common in the language of the documents. It has to be called before
+// the redactor is used.
+func (r *Redactor) AddNames(names ...string) {
+	for _, name := range names {
+		r.names[strings.ToLower(name)] = struct{}{}
+	}
+}
+
+// AddLastNames adds last names to the dictionary that confirms the first
+// names that are also common words, like "Mark" or "Grace". It has to be
+// called before the redactor is used.
+func (r *Redactor) AddLastNames(names ...string) {
+	for _, name := range names {
+		r.lastNames[strings.ToLower(name)] = struct{}{}
+	}
+}
+
+// Detect returns the entities found in the text, in the order they are
+// found. Overlapping entities are resolved in favor of the one starting
+// first, then the longest, then the kind listed first by Kinds.
+func (r *Redactor) Detect(text string) []Entity {
+	var found []Entity
+	priority := make(map[Kind]int)
+
+	for i, d := range r.detectors {
+		priority[d.kind] = i
+
+		for _, e := range d.find(r, text) {
+			e.Kind = d.kind
+			e.Text = text[e.Start:e.End]
+			found = append(found, e)
+		}
+	}
+
+	slices.SortFunc(found, func(a, b Entity) int {
+		switch {
+		case a.Start != b.Start:
+			return a.Start - b.Start
+		case a.End != b.End:
+			return b.End - a.End
+		}
+		return priority[a.Kind] - priority[b.Kind]
+	})
+
+	var entities []Entity
+	var end int
+	for _, e := range found {
+		if e.Start < end {
+			continue
+		}
+		entities = append(entities, e)
+		end = e.End
+	}
+
+	return entities
+}
+
+// Redact returns the text with its entities replaced, and the redactions
+// made. The redactions are written to the log of the configuration.
+func (r *Redactor) Redact(text string) (string, []Redaction, error) {
+	entities := r.Detect(text)
+	if len(entities) == 0 {
+		return text, nil, nil
+	}
+
+	var b strings.Builder
+	var pos int
+
+	redactions := make([]Redaction, len(entities))
+	for i, e := range entities {
+		replacement, err := r.replacement(e)
+		if err != nil {
+			return "", nil, err
+		}
+
+		b.WriteString(text[pos:e.Start])
+		b.WriteString(replacement)
+		pos = e.End
+
+		redactions[i] = Redaction{
+			Kind:        e.Kind,
+			Start:       e.Start,
+			End:         e.End,
+			Replacement: replacement,
+		}
+	}
+	b.WriteString(text[pos:])
+
+	if err := r.log(redactions); err != nil {
+		return "", nil, err
+	}
+
+	return b.String(), redactions, nil
+}
+
+// Restore replaces the tokens of a text redacted by this redactor, or one
+// with the same vault, by their original values, whatever their case.
+// Unknown tokens are left alone.
+func (r *Redactor) Restore(text string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return tokenPattern.ReplaceAllStringFunc(text, func(token string) string {
+		if value, exists := r.vault[strings.ToLower(token)]; exists {
+			return value
+		}
+		return token
+	})
+}
+
+// Counts returns the number of entities redacted by kind.
+func (r *Redactor) Counts() map[Kind]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts := make(map[Kind]int, len(r.counts))
+	for kind, n := range r.counts {
+		counts[kind] = n
+	}
+
+	return counts
+}
+
+// =============================================================================
+
+// SaveVault writes the tokens and their original values to a file, so the
+// documents can be restored by another process with LoadVault. The file holds
+// the personal data the documents were cleaned of and has to be protected as
+// such.
+func (r *Redactor) SaveVault(fileName string) error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.vault, "", "  ")
+	r.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	if err := os.WriteFile(fileName, data, 0600); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadVault adds the tokens of a file written by SaveVault. A token already
+// kept for another value is an error and none of the tokens are added.
+func (r *Redactor) LoadVault(fileName string) error {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	var vault map[string]string
+	if err := json.Unmarshal(data, &vault); err != nil {
+		return fmt.Errorf("unmarshal: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for token, value := range vault {
+		if current, exists := r.vault[token]; exists && current != value {
+			return fmt.Errorf("token %s already holds another value", token)
+		}
+	}
+
+	for token, value := range vault {
+		r.vault[token] = value
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+var tokenPattern = regexp.MustCompile(`(?i)\bpii_[a-z_]+_[a-p]{16}\b`)
+
+// replacement returns the placeholder or token of the entity, keeping the
+// value of a token in the vault. A token already kept for another value is
+// an error, since restoring it would give back the wrong value.
+func (r *Redactor) replacement(e Entity) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.config.Reversible {
+		r.counts[e.Kind]++
+		return "[" + string(e.Kind) + "]", nil
+	}
+
+	mac := hmac.New(sha256.New, r.config.Secret)
+	mac.Write([]byte(e.Kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(e.Text))
+
+	token := "pii_" + strings.ToLower(string(e.Kind)) + "_" + letters(mac.Sum(nil)[:8])
+
+	if value, exists := r.vault[token]; exists && value != e.Text {
+		return "", fmt.Errorf("token %s already holds another value", token)
+	}
+
+	r.vault[token] = e.Text
+	r.counts[e.Kind]++
+
+	return token, nil
+}
+
+// letters encodes the bytes like hexadecimal, with the letters a to p in
+// place of the digits, since the stages that drop the numbers of a text
+// would break a token apart.
+func letters(b []byte) string {
+	s := make([]byte, 0, 2*len(b))
+	for _, c := range b {
+		s = append(s, 'a'+c>>4, 'a'+c&0x0f)
+	}
+
+	return string(s)
+}
+
+// log writes the redactions to the log of the configuration.
+func (r *Redactor) log(redactions []Redaction) error {
+	if r.config.Log == nil {
+		return nil
+	}
+
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, rd := range redactions {
+		if err := enc.Encode(rd); err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := io.WriteString(r.config.Log, b.String()); err != nil {
+		return fmt.Errorf("write log: %w", err)
+	}
+
+	return nil
+}
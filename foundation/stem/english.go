@@ -0,0 +1,350 @@
+package stem
+
+import (
+	"unicode/utf8"
+)
+
+// English returns the stem of an English word following the Porter2 algorithm
+// of the Snowball project:
+// https://snowballstem.org/algorithms/english/stemmer.html
+func English(s string) string {
+	if utf8.RuneCountInString(s) <= 2 {
+		return s
+	}
+
+	if stem, exists := englishExceptions[s]; exists {
+		return stem
+	}
+
+	w := word(s)
+	if w[0] == '\'' {
+		w = w[1:]
+	}
+
+	// A y used as a consonant is marked as Y, so it is not a vowel.
+	for i := range w {
+		if w[i] == 'y' && (i == 0 || englishVowel(w[i-1])) {
+			w[i] = 'Y'
+		}
+	}
+
+	r1 := englishR1(w)
+	r2 := region(w, r1, englishVowel)
+
+	w = englishStep0(w)
+	w = englishStep1a(w)
+
+	if _, exists := englishInvariants[string(w)]; exists {
+		return string(w)
+	}
+
+	w = englishStep1b(w, r1)
+	w = englishStep1c(w)
+	w = englishStep2(w, r1)
+	w = englishStep3(w, r1, r2)
+	w = englishStep4(w, r2)
+	w = englishStep5(w, r1, r2)
+
+	for i := range w {
+		if w[i] == 'Y' {
+			w[i] = 'y'
+		}
+	}
+
+	return string(w)
+}
+
+// =============================================================================
+
+// englishExceptions lists the words with an irregular stem.
+var englishExceptions = map[string]string{
+	"skis":   "ski",
+	"skies":  "sky",
+	"dying":  "die",
+	"lying":  "lie",
+	"tying":  "tie",
+	"idly":   "idl",
+	"gently": "gentl",
+	"ugly":   "ugli",
+	"early":  "earli",
+	"only":   "onli",
+	"singly": "singl",
+	"sky":    "sky",
+	"news":   "news",
+	"howe":   "howe",
+	"atlas":  "atlas",
+	"cosmos": "cosmos",
+	"bias":   "bias",
+	"andes":  "andes",
+}
+
+// englishInvariants lists the words left alone once their plural is removed.
+var englishInvariants = map[string]struct{}{
+	"inning":  {},
+	"outing":  {},
+	"canning": {},
+	"herring": {},
+	"earring": {},
+	"proceed": {},
+	"exceed":  {},
+	"succeed": {},
+}
+
+func englishVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+	return false
+}
+
+func englishDouble(w word) bool {
+	if len(w) < 2 || w[len(w)-1] != w[len(w)-2] {
+		return false
+	}
+
+	switch w[len(w)-1] {
+	case 'b', 'd', 'f', 'g', 'm', 'n', 'p', 'r', 't':
+		return true
+	}
+	return false
+}
+
+func englishLiEnding(r rune) bool {
+	switch r {
+	case 'c', 'd', 'e', 'g', 'h', 'k', 'm', 'n', 'r', 't':
+		return true
+	}
+	return false
+}
+
+// englishShortSyllable reports whether the word ends with a non-vowel, a
+// vowel and a non-vowel other than w, x or Y, or is a vowel followed by a
+// non-vowel.
+func englishShortSyllable(w word) bool {
+	n := len(w)
+
+	switch {
+	case n == 2:
+		return englishVowel(w[0]) && !englishVowel(w[1])
+
+	case n > 2:
+		last := w[n-1]
+		return !englishVowel(w[n-3]) && englishVowel(w[n-2]) && !englishVowel(last) &&
+			last != 'w' && last != 'x' && last != 'Y'
+	}
+
+	return false
+}
+
+func englishContainsVowel(w word) bool {
+	for _, r := range w {
+		if englishVowel(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func englishR1(w word) int {
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if len(w) >= len(prefix) && string(w[:len(prefix)]) == prefix {
+			return len(prefix)
+		}
+	}
+
+	return region(w, 0, englishVowel)
+}
+
+// =============================================================================
+
+func englishStep0(w word) word {
+	if s, _, found := w.longest([]string{"'s'", "'s", "'"}); found {
+		return w[:len(w)-len(s)]
+	}
+	return w
+}
+
+func englishStep1a(w word) word {
+	s, start, found := w.longest([]string{"sses", "ied", "ies", "us", "ss", "s"})
+	if !found {
+		return w
+	}
+
+	switch s {
+	case "sses":
+		return w.replace(4, "ss")
+
+	case "ied", "ies":
+		if start > 1 {
+			return w.replace(3, "i")
+		}
+		return w.replace(3, "ie")
+
+	case "s":
+		// The s goes when a vowel comes before the letter preceding it.
+		if len(w) > 2 && englishContainsVowel(w[:len(w)-2]) {
+			return w[:len(w)-1]
+		}
+	}
+
+	return w
+}
+
+func englishStep1b(w word, r1 int) word {
+	s, start, found := w.longest([]string{"eed", "eedly", "ed", "edly", "ing", "ingly"})
+	if !found {
+		return w
+	}
+
+	switch s {
+	case "eed", "eedly":
+		if start >= r1 {
+			return w.replace(len(s), "ee")
+		}
+		return w
+	}
+
+	if !englishContainsVowel(w[:start]) {
+		return w
+	}
+
+	w = w[:start]
+
+	switch {
+	case w.hasSuffix("at"), w.hasSuffix("bl"), w.hasSuffix("iz"):
+		return append(w, 'e')
+
+	case englishDouble(w):
+		return w[:len(w)-1]
+
+	case r1 >= len(w) && englishShortSyllable(w):
+		return append(w, 'e')
+	}
+
+	return w
+}
+
+func englishStep1c(w word) word {
+	n := len(w)
+	if n > 2 && (w[n-1] == 'y' || w[n-1] == 'Y') && !englishVowel(w[n-2]) {
+		w[n-1] = 'i'
+	}
+	return w
+}
+
+var englishStep2Suffixes = map[string]string{
+	"tional":  "tion",
+	"enci":    "ence",
+	"anci":    "ance",
+	"abli":    "able",
+	"entli":   "ent",
+	"izer":    "ize",
+	"ization": "ize",
+	"ational": "ate",
+	"ation":   "ate",
+	"ator":    "ate",
+	"alism":   "al",
+	"aliti":   "al",
+	"alli":    "al",
+	"fulness": "ful",
+	"ousli":   "ous",
+	"ousness": "ous",
+	"iveness": "ive",
+	"iviti":   "ive",
+	"biliti":  "ble",
+	"bli":     "ble",
+	"ogi":     "og",
+	"fulli":   "ful",
+	"lessli":  "less",
+	"li":      "",
+}
+
+var englishStep2List = keys(englishStep2Suffixes)
+
+func englishStep2(w word, r1 int) word {
+	s, start, found := w.longest(englishStep2List)
+	if !found || start < r1 {
+		return w
+	}
+
+	switch s {
+	case "ogi":
+		if start == 0 || w[start-1] != 'l' {
+			return w
+		}
+
+	case "li":
+		if start == 0 || !englishLiEnding(w[start-1]) {
+			return w
+		}
+	}
+
+	return w.replace(len(s), englishStep2Suffixes[s])
+}
+
+var englishStep3Suffixes = map[string]string{
+	"tional":  "tion",
+	"ational": "ate",
+	"alize":   "al",
+	"icate":   "ic",
+	"iciti":   "ic",
+	"ical":    "ic",
+	"ful":     "",
+	"ness":    "",
+	"ative":   "",
+}
+
+var englishStep3List = keys(englishStep3Suffixes)
+
+func englishStep3(w word, r1 int, r2 int) word {
+	s, start, found := w.longest(englishStep3List)
+	if !found || start < r1 {
+		return w
+	}
+
+	if s == "ative" && start < r2 {
+		return w
+	}
+
+	return w.replace(len(s), englishStep3Suffixes[s])
+}
+
+var englishStep4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+}
+
+func englishStep4(w word, r2 int) word {
+	s, start, found := w.longest(englishStep4Suffixes)
+	if !found || start < r2 {
+		return w
+	}
+
+	if s == "ion" && (start == 0 || (w[start-1] != 's' && w[start-1] != 't')) {
+		return w
+	}
+
+	return w[:start]
+}
+
+func englishStep5(w word, r1 int, r2 int) word {
+	n := len(w)
+	if n == 0 {
+		return w
+	}
+
+	switch w[n-1] {
+	case 'e':
+		if n-1 >= r2 || (n-1 >= r1 && !englishShortSyllable(w[:n-1])) {
+			return w[:n-1]
+		}
+
+	case 'l':
+		if n-1 >= r2 && n > 1 && w[n-2] == 'l' {
+			return w[:n-1]
+		}
+	}
+
+	return w
+}
@@ -0,0 +1,571 @@
+package stem
+
+// englishLemmas lists the English word forms whose lemma the suffix rules do
+// not find, like irregular verbs and plurals, and the words the rules would
+// wrongly shorten. Every line holds the form and its lemma.
+var englishLemmas = `
+access	access
+accesses	access
+address	address
+addresses	address
+alumni	alumnus
+alumnus	alumnus
+always	always
+am	be
+analyses	analysis
+analysis	analysis
+anything	anything
+anyway	anyway
+appendices	appendix
+appendix	appendix
+are	be
+arise	arise
+arisen	arise
+arose	arise
+ate	eat
+awake	awake
+awoke	awake
+awoken	awake
+axes	axis
+axis	axis
+bacteria	bacterium
+bacterium	bacterium
+bad	bad
+base	base
+bases	base
+basis	basis
+be	be
+bear	bear
+beat	beat
+beaten	beat
+became	become
+become	become
+been	be
+began	begin
+begin	begin
+begun	begin
+being	be
+bend	bend
+bent	bend
+best	good
+bet	bet
+better	good
+bid	bid
+bind	bind
+bit	bit
+bite	bite
+bitten	bite
+bled	bleed
+bleed	bleed
+blew	blow
+blow	blow
+blown	blow
+bore	bear
+born	bear
+borne	bear
+bought	buy
+bound	bound
+box	box
+boxes	box
+break	break
+bred	breed
+breed	breed
+bring	bring
+broke	break
+broken	break
+brought	bring
+build	build
+built	build
+burn	burn
+burnt	burn
+burst	burst
+bus	bus
+buses	bus
+business	business
+businesses	business
+buy	buy
+cable	cable
+cables	cable
+cacti	cactus
+cactus	cactus
+calf	calf
+calves	calf
+came	come
+can	can
+canoe	canoe
+canoes	canoe
+case	case
+cases	case
+catch	catch
+caught	catch
+cause	cause
+causes	cause
+ceiling	ceiling
+child	child
+children	child
+choose	choose
+chose	choose
+chosen	choose
+class	class
+classes	class
+cling	cling
+clung	cling
+come	come
+cookie	cookie
+cookies	cookie
+cost	cost
+could	can
+course	course
+courses	course
+creep	creep
+crept	creep
+crises	crisis
+crisis	crisis
+criteria	criterion
+criterion	criterion
+curricula	curriculum
+curriculum	curriculum
+cut	cut
+data	data
+datum	datum
+deal	deal
+dealt	deal
+deer	deer
+device	device
+devices	device
+diagnoses	diagnosis
+diagnosis	diagnosis
+dice	die
+did	do
+die	die
+dies	die
+dig	dig
+do	do
+does	do
+done	do
+drank	drink
+draw	draw
+drawn	draw
+dream	dream
+dreamt	dream
+drew	draw
+drink	drink
+drive	drive
+driven	drive
+drove	drive
+drunk	drink
+dug	dig
+during	during
+dying	die
+earphones	earphones
+eat	eat
+eaten	eat
+echo	echo
+echoes	echo
+electronics	electronics
+evening	evening
+everything	everything
+fall	fall
+fallen	fall
+fed	feed
+feed	feed
+feel	feel
+feet	foot
+fell	fall
+felt	feel
+fight	fight
+find	find
+fish	fish
+fled	flee
+flee	flee
+flew	fly
+flies	fly
+fling	fling
+flown	fly
+flung	fling
+fly	fly
+foot	foot
+forbade	forbid
+forbid	forbid
+forbidden	forbid
+forgave	forgive
+forget	forget
+forgive	forgive
+forgiven	forgive
+forgot	forget
+forgotten	forget
+fought	fight
+found	found
+freeze	freeze
+froze	freeze
+frozen	freeze
+fungi	fungus
+fungus	fungus
+gas	gas
+gases	gas
+gave	give
+geese	goose
+get	get
+give	give
+given	give
+glass	glass
+glasses	glasses
+go	go
+goes	go
+gone	go
+good	good
+goods	goods
+goose	goose
+got	get
+gotten	get
+grew	grow
+grind	grind
+ground	ground
+grow	grow
+grown	grow
+had	have
+half	half
+halves	half
+hang	hang
+has	have
+have	have
+having	have
+headphones	headphones
+hear	hear
+heard	hear
+held	hold
+hero	hero
+heroes	hero
+hid	hide
+hidden	hide
+hide	hide
+hit	hit
+hold	hold
+horse	horse
+horses	horse
+house	house
+houses	house
+hung	hang
+hurt	hurt
+hypotheses	hypothesis
+hypothesis	hypothesis
+index	index
+indices	index
+is	be
+issue	issue
+issues	issue
+jeans	jeans
+keep	keep
+kept	keep
+kneel	kneel
+knelt	kneel
+knew	know
+knife	knife
+knives	knife
+know	know
+known	know
+laid	lay
+lay	lay
+lead	lead
+leaf	leaf
+lean	lean
+leant	lean
+leap	leap
+leapt	leap
+learn	learn
+learnt	learn
+leave	leave
+leaves	leaf
+led	lead
+left	left
+lend	lend
+lens	lens
+lenses	lens
+lent	lend
+let	let
+lice	louse
+lie	lie
+lies	lie
+life	life
+light	light
+lit	lit
+lives	life
+loaf	loaf
+loaves	loaf
+lose	lose
+lost	lose
+louse	louse
+lying	lie
+made	make
+make	make
+man	man
+matrices	matrix
+matrix	matrix
+may	may
+mean	mean
+meant	mean
+media	media
+medium	medium
+meet	meet
+men	man
+met	meet
+mice	mouse
+might	may
+morning	morning
+mouse	mouse
+movie	movie
+movies	movie
+news	news
+nothing	nothing
+nuclei	nucleus
+nucleus	nucleus
+ox	ox
+oxen	ox
+paid	pay
+pants	pants
+pay	pay
+people	person
+perhaps	perhaps
+person	person
+phenomena	phenomenon
+phenomenon	phenomenon
+phone	phone
+phones	phone
+photo	photo
+photos	photo
+piano	piano
+pianos	piano
+pie	pie
+pies	pie
+potato	potato
+potatoes	potato
+price	price
+prices	price
+process	process
+processes	process
+prove	prove
+proven	prove
+pudding	pudding
+purchase	purchase
+purchases	purchase
+put	put
+quit	quit
+radii	radius
+radio	radio
+radios	radio
+radius	radius
+ran	run
+rang	ring
+read	read
+release	release
+releases	release
+response	response
+responses	response
+ridden	ride
+ride	ride
+ring	ring
+rise	rise
+risen	rise
+rode	ride
+rose	rose
+run	run
+rung	ring
+said	say
+sang	sing
+sank	sink
+sat	sit
+saw	saw
+say	say
+scissors	scissors
+see	see
+seek	seek
+seen	see
+self	self
+sell	sell
+selves	self
+send	send
+sent	send
+series	series
+service	service
+services	service
+set	set
+sew	sew
+sewn	sew
+shake	shake
+shaken	shake
+shall	shall
+shed	shed
+sheep	sheep
+shelf	shelf
+shelves	shelf
+shine	shine
+shoe	shoe
+shoes	shoe
+shone	shine
+shook	shake
+shoot	shoot
+shorts	shorts
+shot	shoot
+should	shall
+show	show
+shown	show
+shrank	shrink
+shrink	shrink
+shrunk	shrink
+shut	shut
+sing	sing
+sink	sink
+sit	sit
+size	size
+sizes	size
+sleep	sleep
+slept	sleep
+slid	slide
+slide	slide
+sling	sling
+slung	sling
+sold	sell
+something	something
+sought	seek
+speak	speak
+species	species
+sped	speed
+speed	speed
+spend	spend
+spent	spend
+spin	spin
+spit	spit
+split	split
+spoke	speak
+spoken	speak
+sprang	spring
+spread	spread
+spring	spring
+sprung	spring
+spun	spin
+stand	stand
+stank	stink
+status	status
+statuses	status
+steal	steal
+stereo	stereo
+stereos	stereo
+stick	stick
+stimuli	stimulus
+stimulus	stimulus
+sting	sting
+stink	stink
+stole	steal
+stolen	steal
+stood	stand
+strike	strike
+string	string
+strive	strive
+striven	strive
+strove	strive
+struck	strike
+strung	string
+stuck	stick
+studio	studio
+studios	studio
+stung	sting
+stunk	stink
+sung	sing
+sunk	sink
+swam	swim
+swear	swear
+sweep	sweep
+swept	sweep
+swim	swim
+swing	swing
+swore	swear
+sworn	swear
+swum	swim
+swung	swing
+syllabi	syllabus
+syllabus	syllabus
+take	take
+taken	take
+taught	teach
+teach	teach
+tear	tear
+teeth	tooth
+tell	tell
+theses	thesis
+thesis	thesis
+thief	thief
+thieves	thief
+thing	thing
+think	think
+thought	think
+threw	throw
+throw	throw
+thrown	throw
+tie	tie
+ties	tie
+toe	toe
+toes	toe
+told	tell
+tomato	tomato
+tomatoes	tomato
+took	take
+tooth	tooth
+tore	tear
+torn	tear
+tread	tread
+trod	tread
+trodden	tread
+tying	tie
+understand	understand
+understood	understand
+undid	undo
+undo	undo
+undone	undo
+upset	upset
+use	use
+uses	use
+value	value
+values	value
+vertex	vertex
+vertices	vertex
+video	video
+videos	video
+virus	virus
+viruses	virus
+wake	wake
+was	be
+wear	wear
+weave	weave
+weep	weep
+went	go
+wept	weep
+were	be
+wife	wife
+will	will
+win	win
+wind	wind
+withdraw	withdraw
+withdrawn	withdraw
+withdrew	withdraw
+wives	wife
+woke	wake
+woken	wake
+wolf	wolf
+wolves	wolf
+woman	woman
+women	woman
+won	win
+wore	wear
+worn	wear
+worse	bad
+worst	bad
+would	will
+wound	wound
+wove	weave
+woven	weave
+write	write
+written	write
+wrote	write
+zero	zero
+zeros	zero
+`
@@ -0,0 +1,222 @@
+package stem
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Lemmatizer maps English words to their dictionary form, like "batteries" to
+// "battery" and "charged" to "charge". Forms listed in its dictionary, like
+// the built in irregular verbs and plurals, are looked up. Other words go
+// through suffix rules in the manner of WordNet's morphy.
+//
+// When a lexicon of known lemmas is provided, like the vocabulary of a model
+// or a lemma list, the rules only produce words of the lexicon and leave the
+// word alone otherwise. Without a lexicon, the rules guess when a final e has
+// to be restored, which is right for most but not all words.
+//
+// A lemmatizer is safe for concurrent use once it is no longer modified.
+type Lemmatizer struct {
+	lemmas  map[string]string
+	lexicon map[string]struct{}
+}
+
+// NewLemmatizer constructs a lemmatizer with the built in dictionary of
+// irregular forms.
+func NewLemmatizer() *Lemmatizer {
+	l := Lemmatizer{
+		lemmas:  make(map[string]string),
+		lexicon: make(map[string]struct{}),
+	}
+
+	for _, line := range strings.Split(englishLemmas, "\n") {
+		if form, lemma, found := strings.Cut(line, "\t"); found {
+			l.lemmas[form] = lemma
+		}
+	}
+
+	return &l
+}
+
+// LoadLemmatizer constructs a lemmatizer with the built in dictionary plus
+// the one of the file. Every line of the file holds a lemma and one of its
+// forms separated by a tab, like the lists of the lemmatization-lists project:
+// https://github.com/michmech/lemmatization-lists
+//
+// A line with a single word adds it to the lexicon. Every lemma of the file
+// is added to the lexicon too.
+func LoadLemmatizer(fileName string) (*Lemmatizer, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer file.Close()
+
+	l := NewLemmatizer()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(strings.ToLower(scanner.Text()))
+
+		switch len(fields) {
+		case 0:
+		case 1:
+			l.AddWords(fields[0])
+		default:
+			l.Add(fields[1], fields[0])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	return l, nil
+}
+
+// Add adds a form and its lemma to the dictionary, and the lemma to the
+// lexicon.
+func (l *Lemmatizer) Add(form string, lemma string) {
+	l.lemmas[form] = lemma
+	l.lexicon[lemma] = struct{}{}
+}
+
+// AddWords adds known lemmas to the lexicon the suffix rules are checked
+// against.
+func (l *Lemmatizer) AddWords(words ...string) {
+	for _, word := range words {
+		l.lexicon[word] = struct{}{}
+	}
+}
+
+// Lemma returns the lemma of the word.
+func (l *Lemmatizer) Lemma(word string) string {
+	if lemma, exists := l.lemmas[word]; exists {
+		return lemma
+	}
+
+	if len(l.lexicon) > 0 {
+		for _, candidate := range lemmaCandidates(word) {
+			if _, exists := l.lexicon[candidate]; exists {
+				return candidate
+			}
+		}
+		return word
+	}
+
+	return guessLemma(word)
+}
+
+// =============================================================================
+
+// lemmaRule represents the replacement of a suffix by another.
+type lemmaRule struct {
+	suffix  string
+	replace string
+}
+
+// lemmaRules lists the noun, verb and adjective rules in the order their
+// results are tried against the lexicon.
+var lemmaRules = []lemmaRule{
+	{"ies", "y"}, {"ses", "s"}, {"xes", "x"}, {"zes", "z"}, {"ches", "ch"},
+	{"shes", "sh"}, {"men", "man"}, {"s", ""},
+	{"ied", "y"}, {"es", "e"}, {"es", ""}, {"ed", "e"}, {"ed", ""},
+	{"ing", "e"}, {"ing", ""},
+	{"est", ""}, {"er", ""}, {"est", "e"}, {"er", "e"},
+}
+
+// lemmaCandidates returns the words the rules produce, including the ones
+// of a doubled final consonant undone, like "stop" of "stopped".
+func lemmaCandidates(s string) []string {
+	var candidates []string
+
+	for _, rule := range lemmaRules {
+		base, found := strings.CutSuffix(s, rule.suffix)
+		if !found || len(base) < 2 {
+			continue
+		}
+
+		candidates = append(candidates, base+rule.replace)
+
+		if rule.replace == "" && englishDouble(word(base)) {
+			candidates = append(candidates, base[:len(base)-1])
+		}
+	}
+
+	return candidates
+}
+
+// guessLemma applies the noun and verb rules without a lexicon, leaving
+// alone words too short or with endings that are rarely inflections.
+func guessLemma(s string) string {
+	if len(s) <= 3 {
+		return s
+	}
+
+	switch {
+	case strings.HasSuffix(s, "ss"), strings.HasSuffix(s, "us"), strings.HasSuffix(s, "is"),
+		strings.HasSuffix(s, "eed"):
+		return s
+
+	case strings.HasSuffix(s, "ies"), strings.HasSuffix(s, "ied"):
+		return s[:len(s)-3] + "y"
+
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"), strings.HasSuffix(s, "zzes"),
+		strings.HasSuffix(s, "ches"), strings.HasSuffix(s, "shes"):
+		return s[:len(s)-2]
+
+	case strings.HasSuffix(s, "s"):
+		return s[:len(s)-1]
+
+	case strings.HasSuffix(s, "ed"):
+		return restoreLemma(s, s[:len(s)-2])
+
+	case strings.HasSuffix(s, "ing"):
+		return restoreLemma(s, s[:len(s)-3])
+	}
+
+	return s
+}
+
+// restoreLemma undoes the spelling changes of adding ed or ing to a verb: a
+// doubled final consonant is undone and a dropped final e is restored.
+func restoreLemma(s string, base string) string {
+	w := word(base)
+	if len(w) < 2 || !englishContainsVowel(w) {
+		return s
+	}
+
+	switch {
+	case englishDouble(w):
+		return base[:len(base)-1]
+
+	case region(w, 0, englishVowel) >= len(w) && englishShortSyllable(w):
+		return base + "e"
+	}
+
+	for _, ending := range []string{"at", "bl", "iz", "yz", "rg", "dg", "lv", "rv", "c", "v", "uir", "pir", "mir", "sir"} {
+		if strings.HasSuffix(base, ending) {
+			return base + "e"
+		}
+	}
+
+	// Endings restored on longer words only, like "change" and "manage", but
+	// not "hang" or "bag".
+	for _, ending := range []string{"ang", "ag"} {
+		if strings.HasSuffix(base, ending) && len(base) >= 5 {
+			return base + "e"
+		}
+	}
+
+	// Endings restored only after a consonant, like "decide" and "include",
+	// but not "avoid" or "load".
+	for _, ending := range []string{"id", "ud", "os"} {
+		if n := len(base) - len(ending); strings.HasSuffix(base, ending) && n > 0 && !englishVowel(rune(base[n-1])) {
+			return base + "e"
+		}
+	}
+
+	return base
+}
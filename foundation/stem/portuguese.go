@@ -0,0 +1,233 @@
+package stem
+
+import (
+	"strings"
+)
+
+// Portuguese returns the stem of a Portuguese word following the algorithm of
+// the Snowball project:
+// https://snowballstem.org/algorithms/portuguese/stemmer.html
+func Portuguese(s string) string {
+	// The nasal vowels are handled as a vowel followed by a ~, which is not a
+	// vowel, the same way the Snowball algorithm does.
+	s = strings.NewReplacer("ã", "a~", "õ", "o~").Replace(s)
+
+	w := word(s)
+
+	rv := portugueseRV(w)
+	r1 := region(w, 0, portugueseVowel)
+	r2 := region(w, r1, portugueseVowel)
+
+	var altered bool
+	if w, altered = portugueseStep1(w, r1, r2, rv); !altered {
+		w, altered = portugueseStep2(w, rv)
+	}
+
+	switch altered {
+	case true:
+		// Step 3: an i left by the removed suffix after a c goes too.
+		if n := len(w); n-1 >= rv && n > 1 && w[n-1] == 'i' && w[n-2] == 'c' {
+			w = w[:n-1]
+		}
+
+	default:
+		w = portugueseStep4(w, rv)
+	}
+
+	w = portugueseStep5(w, rv)
+
+	return strings.NewReplacer("a~", "ã", "o~", "õ").Replace(string(w))
+}
+
+// =============================================================================
+
+func portugueseVowel(r rune) bool {
+	switch r {
+	case 'a', 'e', 'i', 'o', 'u', 'á', 'é', 'í', 'ó', 'ú', 'â', 'ê', 'ô':
+		return true
+	}
+	return false
+}
+
+// portugueseRV returns the start of the RV region. When the second letter is
+// a consonant, RV is the region after the next vowel. When the first two
+// letters are vowels, RV is the region after the next consonant. Otherwise RV
+// is the region after the third letter.
+func portugueseRV(w word) int {
+	if len(w) < 2 {
+		return len(w)
+	}
+
+	switch {
+	case !portugueseVowel(w[1]):
+		for i := 2; i < len(w); i++ {
+			if portugueseVowel(w[i]) {
+				return i + 1
+			}
+		}
+
+	case portugueseVowel(w[0]):
+		for i := 2; i < len(w); i++ {
+			if !portugueseVowel(w[i]) {
+				return i + 1
+			}
+		}
+
+	default:
+		if len(w) >= 3 {
+			return 3
+		}
+	}
+
+	return len(w)
+}
+
+// =============================================================================
+
+var portugueseStep1Suffixes = map[string]int{
+	"eza": 1, "ezas": 1, "ico": 1, "ica": 1, "icos": 1, "icas": 1, "ismo": 1,
+	"ismos": 1, "ável": 1, "ível": 1, "ista": 1, "istas": 1, "oso": 1,
+	"osa": 1, "osos": 1, "osas": 1, "amento": 1, "amentos": 1, "imento": 1,
+	"imentos": 1, "adora": 1, "ador": 1, "aça~o": 1, "adoras": 1,
+	"adores": 1, "aço~es": 1, "ante": 1, "antes": 1, "ância": 1,
+
+	"logia": 2, "logias": 2,
+	"uça~o": 3, "uço~es": 3,
+	"ência": 4, "ências": 4,
+	"amente": 5,
+	"mente":  6,
+	"idade":  7, "idades": 7,
+	"iva": 8, "ivo": 8, "ivas": 8, "ivos": 8,
+	"ira": 9, "iras": 9,
+}
+
+var portugueseStep1List = keys(portugueseStep1Suffixes)
+
+// portugueseStep1 removes the standard suffixes and reports whether the word
+// was altered.
+func portugueseStep1(w word, r1 int, r2 int, rv int) (word, bool) {
+	s, start, found := w.longest(portugueseStep1List)
+	if !found {
+		return w, false
+	}
+
+	// try removes the longest of the suffixes that follow the removed one
+	// when it is in R2.
+	try := func(w word, suffixes ...string) word {
+		if _, start, found := w.longest(suffixes); found && start >= r2 {
+			return w[:start]
+		}
+		return w
+	}
+
+	switch portugueseStep1Suffixes[s] {
+	case 1:
+		if start >= r2 {
+			return w[:start], true
+		}
+
+	case 2:
+		if start >= r2 {
+			return w.replace(len([]rune(s)), "log"), true
+		}
+
+	case 3:
+		if start >= r2 {
+			return w.replace(len([]rune(s)), "u"), true
+		}
+
+	case 4:
+		if start >= r2 {
+			return w.replace(len([]rune(s)), "ente"), true
+		}
+
+	case 5:
+		if start >= r1 {
+			w = w[:start]
+			if w.hasSuffix("iv") && len(w)-2 >= r2 {
+				return try(w[:len(w)-2], "at"), true
+			}
+			return try(w, "os", "ic", "ad"), true
+		}
+
+	case 6:
+		if start >= r2 {
+			return try(w[:start], "ante", "avel", "ível"), true
+		}
+
+	case 7:
+		if start >= r2 {
+			return try(w[:start], "abil", "ic", "iv"), true
+		}
+
+	case 8:
+		if start >= r2 {
+			return try(w[:start], "at"), true
+		}
+
+	case 9:
+		if start >= rv && start > 0 && w[start-1] == 'e' {
+			return w.replace(len([]rune(s)), "ir"), true
+		}
+	}
+
+	return w, false
+}
+
+var portugueseStep2List = []string{
+	"ada", "ida", "ia", "aria", "eria", "iria", "ará", "ara", "erá", "era",
+	"irá", "ava", "asse", "esse", "isse", "aste", "este", "iste", "ei",
+	"arei", "erei", "irei", "am", "iam", "ariam", "eriam", "iriam", "aram",
+	"eram", "iram", "avam", "em", "arem", "erem", "irem", "assem", "essem",
+	"issem", "ado", "ido", "ando", "endo", "indo", "ara~o", "era~o", "ira~o",
+	"ar", "er", "ir", "as", "adas", "idas", "ias", "arias", "erias", "irias",
+	"arás", "aras", "erás", "eras", "irás", "avas", "es", "ardes", "erdes",
+	"irdes", "ares", "eres", "ires", "asses", "esses", "isses", "astes",
+	"estes", "istes", "is", "ais", "eis", "íeis", "aríeis", "eríeis",
+	"iríeis", "áreis", "areis", "éreis", "ereis", "íreis", "ireis",
+	"ásseis", "ésseis", "ísseis", "áveis", "ados", "idos", "ámos", "amos",
+	"íamos", "aríamos", "eríamos", "iríamos", "áramos", "éramos", "íramos",
+	"ávamos", "emos", "aremos", "eremos", "iremos", "ássemos", "êssemos",
+	"íssemos", "imos", "armos", "ermos", "irmos", "eu", "iu", "ou", "ira",
+	"iras",
+}
+
+// portugueseStep2 removes the longest verb suffix found in RV and reports
+// whether the word was altered.
+func portugueseStep2(w word, rv int) (word, bool) {
+	if _, start, found := w[rv:].longest(portugueseStep2List); found {
+		return w[:rv+start], true
+	}
+
+	return w, false
+}
+
+// portugueseStep4 removes the residual suffixes in RV.
+func portugueseStep4(w word, rv int) word {
+	if _, start, found := w.longest([]string{"os", "a", "i", "o", "á", "í", "ó"}); found && start >= rv {
+		return w[:start]
+	}
+
+	return w
+}
+
+// portugueseStep5 removes a final e in RV, with the u of a preceding gu or
+// the i of a preceding ci, and replaces a final ç by c.
+func portugueseStep5(w word, rv int) word {
+	if _, start, found := w.longest([]string{"e", "é", "ê"}); found && start >= rv {
+		w = w[:start]
+
+		n := len(w)
+		if n-1 >= rv && n > 1 && ((w[n-1] == 'u' && w[n-2] == 'g') || (w[n-1] == 'i' && w[n-2] == 'c')) {
+			w = w[:n-1]
+		}
+
+		return w
+	}
+
+	if n := len(w); n > 0 && w[n-1] == 'ç' {
+		w[n-1] = 'c'
+	}
+
+	return w
+}
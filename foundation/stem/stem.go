@@ -0,0 +1,110 @@
+// Package stem provides support for reducing the inflected forms of a word to
+// a common form, so "battery" and "batteries" or "charged" and "charging" are
+// the same term for a word2vec vocabulary or a lexical index.
+//
+// Stemmers follow the Snowball algorithms and cut words down to a stem that
+// is not always a word, like "batteri". The lemmatizer maps words to their
+// dictionary form, like "battery", using a dictionary of irregular forms and
+// suffix rules. Words are expected in lowercase.
+package stem
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Func represents a function reducing a word to its stem or lemma. The
+// English and Portuguese functions and the Lemma method of a Lemmatizer
+// satisfy it, and it can be provided to a stop word Filter.
+type Func func(word string) string
+
+// ForLanguage returns the stemmer of the language by its ISO 639-1 code. A
+// region, like in "pt-BR", is ignored.
+func ForLanguage(lang string) (Func, error) {
+	code := strings.ToLower(lang)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+
+	switch code {
+	case "en":
+		return English, nil
+	case "pt":
+		return Portuguese, nil
+	}
+
+	return nil, fmt.Errorf("unsupported language %q", lang)
+}
+
+// Words applies the function to every word.
+func Words(fn Func, words []string) []string {
+	result := make([]string, len(words))
+	for i, word := range words {
+		result[i] = fn(word)
+	}
+
+	return result
+}
+
+// =============================================================================
+
+// word represents a word being stemmed, kept as runes since the algorithms
+// work on letters.
+type word []rune
+
+func (w word) hasSuffix(suffix string) bool {
+	s := []rune(suffix)
+	if len(s) > len(w) {
+		return false
+	}
+
+	for i := range s {
+		if w[len(w)-len(s)+i] != s[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// longest returns the longest of the suffixes the word ends with and its
+// start, or false if none.
+func (w word) longest(suffixes []string) (string, int, bool) {
+	var found string
+	var size int
+
+	for _, s := range suffixes {
+		if n := len([]rune(s)); n > size && w.hasSuffix(s) {
+			found, size = s, n
+		}
+	}
+
+	return found, len(w) - size, size > 0
+}
+
+// replace replaces the last n letters of the word.
+func (w word) replace(n int, with string) word {
+	return append(w[:len(w)-n], []rune(with)...)
+}
+
+// region returns the start of the region after the first non-vowel following
+// a vowel, both found at or after start, or the end of the word. It defines
+// the R1 and R2 regions of the Snowball algorithms.
+func region(w word, start int, isVowel func(rune) bool) int {
+	for i := start + 1; i < len(w); i++ {
+		if isVowel(w[i-1]) && !isVowel(w[i]) {
+			return i + 1
+		}
+	}
+
+	return len(w)
+}
+
+// keys returns the keys of the map, as the suffix lists of the steps.
+func keys[V any](m map[string]V) []string {
+	list := make([]string, 0, len(m))
+	for k := range m {
+		list = append(list, k)
+	}
+	return list
+}
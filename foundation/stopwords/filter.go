@@ -0,0 +1,185 @@
+package stopwords
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Filter represents a set of stop words that can be extended and shrunk, like
+// the English list without the negations that carry the sentiment of reviews:
+//
+//	f, err := stopwords.NewFilter("en")
+//	f.Keep("not", "no", "nor")
+//	f.Add("amazon")
+//
+// The kept words can be reduced to their stem or lemma with a function of the
+// stem package, after the stop words are removed:
+//
+//	f.Stem(stem.English)
+//
+// A filter is safe for concurrent use once it is no longer modified.
+type Filter struct {
+	lang  string
+	words map[string]struct{}
+	stem  func(word string) string
+}
+
+// NewFilter constructs a filter starting with the stop words of the specified
+// language. An empty language starts with no stop words.
+func NewFilter(lang string) (*Filter, error) {
+	f := Filter{
+		words: make(map[string]struct{}),
+	}
+
+	if lang == "" {
+		return &f, nil
+	}
+
+	code, words, err := lookup(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	f.lang = code
+	for word := range words {
+		if word != "" {
+			f.words[word] = struct{}{}
+		}
+	}
+
+	return &f, nil
+}
+
+// LoadFilter constructs a filter from a file of stop words, with one word per
+// line like the StopWordsFile of the word2vec trainer. The language defines
+// the casing rules the words are lowercased with, it can be empty.
+func LoadFilter(lang string, fileName string) (*Filter, error) {
+	f := Filter{
+		words: make(map[string]struct{}),
+	}
+
+	if lang != "" {
+		code, _, err := lookup(lang)
+		if err != nil {
+			return nil, err
+		}
+		f.lang = code
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		f.Add(scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	return &f, nil
+}
+
+// Add adds words to the stop words.
+func (f *Filter) Add(words ...string) {
+	for _, word := range words {
+		if word = f.normalize(word); word != "" {
+			f.words[word] = struct{}{}
+		}
+	}
+}
+
+// Keep removes words from the stop words, so they are kept in the text.
+func (f *Filter) Keep(words ...string) {
+	for _, word := range words {
+		delete(f.words, f.normalize(word))
+	}
+}
+
+// Stem sets the function the words kept by Remove and RemoveWords go through,
+// like a stemmer or the Lemma method of a lemmatizer. A nil function keeps
+// the words as they are.
+func (f *Filter) Stem(fn func(word string) string) {
+	f.stem = fn
+}
+
+// Contains reports whether the word is a stop word.
+func (f *Filter) Contains(word string) bool {
+	_, exists := f.words[f.normalize(word)]
+	return exists
+}
+
+// Len returns the number of stop words.
+func (f *Filter) Len() int {
+	return len(f.words)
+}
+
+// Words returns the stop words in alphabetical order. They can be provided
+// to the word2vec trainer as its in memory stop words.
+func (f *Filter) Words() []string {
+	list := make([]string, 0, len(f.words))
+	for word := range f.words {
+		list = append(list, word)
+	}
+	sort.Strings(list)
+
+	return list
+}
+
+// Remove iterates through a list of words and removes stop words.
+func (f *Filter) Remove(input string) string {
+	return remove(input, f.lang, f.words, f.stem)
+}
+
+// RemoveWords returns the words that are not stop words, for text already
+// split into words, like by the tokenizer package. The words are expected to
+// be lowercase already.
+func (f *Filter) RemoveWords(words []string) []string {
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		if !f.Contains(word) {
+			if f.stem != nil {
+				word = f.stem(word)
+			}
+			result = append(result, word)
+		}
+	}
+
+	return result
+}
+
+// Save writes the stop words to a file with one word per line, so it can be
+// used as the StopWordsFile of the word2vec trainer.
+func (f *Filter) Save(fileName string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer file.Close()
+
+	w := bufio.NewWriter(file)
+	for _, word := range f.Words() {
+		w.WriteString(word)
+		w.WriteByte('\n')
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return file.Close()
+}
+
+func (f *Filter) normalize(word string) string {
+	return lowercase(f.lang, norm.NFC.String(strings.TrimSpace(word)))
+}
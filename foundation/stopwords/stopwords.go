@@ -1,37 +1,135 @@
 // Package stopwords provides support for removing stopwords from a sentence.
 // This code was taken from:
 // https://github.com/bbalet/stopwords
+//
+// Stop word lists are provided for several languages, selected by their ISO
+// 639-1 code, like "en" or "pt". Remove works with the English list.
 package stopwords
 
 import (
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+	"unicode"
 
 	"golang.org/x/text/unicode/norm"
 )
 
-var wordSegmenter = regexp.MustCompile(`[\pL\p{Mc}\p{Mn}-_']+`)
+// wordSegmenter matches the words of a text. The hyphen goes last in the
+// class, as in between two characters it defines a range. For a tokenizer
+// aware of URLs, numbers and identifiers, see the tokenizer package and the
+// Filter.RemoveWords method.
+var wordSegmenter = regexp.MustCompile(`[\pL\p{Mc}\p{Mn}_'-]+`)
 var stopWords = make(map[string]struct{})
 
-// init will load the stop word list.
+// lists maps the ISO 639-1 code of every supported language to its stop word
+// list, with one word per line.
+var lists = map[string]string{
+	"da": danish,
+	"de": german,
+	"en": stopWordsList,
+	"es": spanish,
+	"fi": finnish,
+	"fr": french,
+	"it": italian,
+	"nl": dutch,
+	"no": norwegian,
+	"pl": polish,
+	"pt": portuguese,
+	"ru": russian,
+	"sv": swedish,
+	"tr": turkish,
+}
+
+// aliases maps other codes of the supported languages to their code.
+var aliases = map[string]string{
+	"nb": "no",
+	"nn": "no",
+}
+
+var languages = make(map[string]map[string]struct{})
+
+// init will load the stop word lists.
 func init() {
-	wordList := strings.Split(strings.ToLower(stopWordsList), "\n")
-	for _, word := range wordList {
-		stopWords[word] = struct{}{}
+	for lang, list := range lists {
+		words := make(map[string]struct{})
+
+		wordList := strings.Split(lowercase(lang, list), "\n")
+		for _, word := range wordList {
+			words[word] = struct{}{}
+		}
+
+		languages[lang] = words
 	}
+
+	stopWords = languages["en"]
 }
 
 // Remove iterates through a list of words and removes stop words.
 func Remove(input string) string {
+	return remove(input, "en", stopWords, nil)
+}
+
+// RemoveLang iterates through a list of words and removes the stop words of
+// the specified language.
+func RemoveLang(lang string, input string) (string, error) {
+	lang, words, err := lookup(lang)
+	if err != nil {
+		return "", err
+	}
+
+	return remove(input, lang, words, nil), nil
+}
+
+// Languages returns the ISO 639-1 codes of the supported languages in
+// alphabetical order.
+func Languages() []string {
+	langs := make([]string, 0, len(languages))
+	for lang := range languages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	return langs
+}
+
+// Words returns the stop words of the specified language in alphabetical
+// order.
+func Words(lang string) ([]string, error) {
+	_, words, err := lookup(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]string, 0, len(words))
+	for word := range words {
+		if word != "" {
+			list = append(list, word)
+		}
+	}
+	sort.Strings(list)
+
+	return list, nil
+}
+
+// =============================================================================
+
+// remove removes the stop words from the input and applies the stem function
+// to the kept words, when provided.
+func remove(input string, lang string, stopWords map[string]struct{}, stem func(word string) string) string {
 	var result []byte
 
 	input = norm.NFC.String(input)
-	input = strings.ToLower(input)
+	input = lowercase(lang, input)
 
 	words := wordSegmenter.FindAll([]byte(input), -1)
 	for _, w := range words {
 		if _, ok := stopWords[string(w)]; !ok {
-			result = append(result, []byte(w)...)
+			if stem != nil {
+				w = []byte(stem(string(w)))
+			}
+			result = append(result, w...)
 			result = append(result, ' ')
 		}
 	}
@@ -39,6 +137,36 @@ func Remove(input string) string {
 	return string(result)
 }
 
+// lookup finds the stop words of the language. The code is case insensitive
+// and a region, like in "pt-BR", is ignored.
+func lookup(lang string) (string, map[string]struct{}, error) {
+	code := strings.ToLower(lang)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+
+	if alias, exists := aliases[code]; exists {
+		code = alias
+	}
+
+	words, exists := languages[code]
+	if !exists {
+		return "", nil, fmt.Errorf("unsupported language %q", lang)
+	}
+
+	return code, words, nil
+}
+
+// lowercase applies the casing rules of Turkish, where the lowercase of I is
+// the dotless ı, and the standard ones for every other language.
+func lowercase(lang string, s string) string {
+	if lang == "tr" {
+		return strings.ToLowerSpecial(unicode.TurkishCase, s)
+	}
+
+	return strings.ToLower(s)
+}
+
 var stopWordsList = `
 'll
 've
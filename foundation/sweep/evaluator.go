@@ -0,0 +1,78 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/ardanlabs/ai-training/foundation/evaluate"
+	"github.com/ardanlabs/ai-training/foundation/word2vec"
+)
+
+// Similarity scores the models with the Spearman correlation on the word
+// pairs, weighted by the coverage of the pairs so a model is not rewarded for
+// leaving the hard words out of its vocabulary. The vector size of config is
+// set from the model.
+func Similarity(pairs []evaluate.Pair, config evaluate.Config) Evaluator {
+	return func(ctx context.Context, model *word2vec.Model, w2vConfig word2vec.Config) (float64, error) {
+		config.Vector = w2vConfig.Vector.Vector
+
+		result, err := evaluate.Similarity(model, pairs, config)
+		if err != nil {
+			return 0, err
+		}
+
+		if math.IsNaN(result.Spearman) {
+			return 0, errors.New("not enough pairs evaluated")
+		}
+
+		return result.Spearman * result.Coverage(), nil
+	}
+}
+
+// Analogy scores the models with the accuracy on all the analogy questions,
+// counting the questions the model can not evaluate as wrong answers. The
+// vector size of config is set from the model.
+func Analogy(sections []evaluate.Section, config evaluate.Config) Evaluator {
+	return func(ctx context.Context, model *word2vec.Model, w2vConfig word2vec.Config) (float64, error) {
+		config.Vector = w2vConfig.Vector.Vector
+
+		var vocabulary []string
+		for word := range model.Words() {
+			if word != "</s>" {
+				vocabulary = append(vocabulary, word)
+			}
+		}
+
+		result, err := evaluate.Analogy(ctx, model, vocabulary, sections, config)
+		if err != nil {
+			return 0, err
+		}
+
+		if result.Total.Questions == 0 {
+			return 0, errors.New("no analogy questions")
+		}
+
+		return float64(result.Total.Correct) / float64(result.Total.Questions), nil
+	}
+}
+
+// Mean scores the models with the average score of the evaluators.
+func Mean(evaluators ...Evaluator) Evaluator {
+	return func(ctx context.Context, model *word2vec.Model, w2vConfig word2vec.Config) (float64, error) {
+		if len(evaluators) == 0 {
+			return 0, errors.New("no evaluator provided")
+		}
+
+		var sum float64
+		for _, eval := range evaluators {
+			score, err := eval(ctx, model, w2vConfig)
+			if err != nil {
+				return 0, err
+			}
+			sum += score
+		}
+
+		return sum / float64(len(evaluators)), nil
+	}
+}
@@ -0,0 +1,180 @@
+package sweep
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/word2vec"
+)
+
+// Result represents the outcome of training and scoring a candidate.
+type Result struct {
+	Run       int
+	Candidate Candidate
+	Config    word2vec.Config
+	Duration  time.Duration
+	Score     float64
+	Err       error
+}
+
+// Leaderboard represents the results of a sweep from the best score to the
+// worst. Failed runs come last.
+type Leaderboard []Result
+
+// Best returns the result with the best score and false when no run
+// succeeded.
+func (lb Leaderboard) Best() (Result, bool) {
+	if len(lb) == 0 || lb[0].failed() {
+		return Result{}, false
+	}
+	return lb[0], true
+}
+
+func (lb Leaderboard) sort() {
+	sort.SliceStable(lb, func(i, j int) bool {
+		if lb[i].failed() != lb[j].failed() {
+			return !lb[i].failed()
+		}
+		if lb[i].failed() {
+			return lb[i].Run < lb[j].Run
+		}
+		return lb[i].Score > lb[j].Score
+	})
+}
+
+func (r Result) failed() bool {
+	return r.Err != nil || math.IsNaN(r.Score)
+}
+
+// =============================================================================
+
+// entry represents a result in the leaderboard files.
+type entry struct {
+	Rank          int      `json:"rank"`
+	Run           int      `json:"run"`
+	Params        string   `json:"params"`
+	Model         string   `json:"model"`
+	Approximation string   `json:"approximation"`
+	Vector        int      `json:"vector"`
+	Window        int      `json:"window"`
+	Threshold     float64  `json:"threshold"`
+	Frequency     int      `json:"frequency"`
+	Negative      int      `json:"negative"`
+	Epoch         int      `json:"epoch"`
+	Rate          float64  `json:"rate"`
+	MinN          int      `json:"minN"`
+	MaxN          int      `json:"maxN"`
+	Seconds       float64  `json:"seconds"`
+	Score         *float64 `json:"score"`
+	Error         string   `json:"error,omitempty"`
+}
+
+func (lb Leaderboard) entries() []entry {
+	entries := make([]entry, len(lb))
+
+	for i, r := range lb {
+		c := r.Config
+
+		e := entry{
+			Rank:          i + 1,
+			Run:           r.Run,
+			Params:        r.Candidate.String(),
+			Model:         "skip-gram",
+			Approximation: "ns",
+			Vector:        c.Vector.Vector,
+			Window:        c.Vector.Window,
+			Threshold:     c.Vector.Threshold,
+			Frequency:     c.Vector.Frequency,
+			Negative:      c.SizeNegativeSampling,
+			Epoch:         c.Learning.Epoch,
+			Rate:          c.Learning.Rate,
+			MinN:          c.Subword.MinN,
+			MaxN:          c.Subword.MaxN,
+			Seconds:       r.Duration.Seconds(),
+		}
+
+		if c.UseCBOW {
+			e.Model = "cbow"
+		}
+		if c.UseHierarchicalSoftMax {
+			e.Approximation = "hs"
+		}
+
+		if !math.IsNaN(r.Score) {
+			score := r.Score
+			e.Score = &score
+		}
+		if r.Err != nil {
+			e.Error = r.Err.Error()
+		}
+
+		entries[i] = e
+	}
+
+	return entries
+}
+
+// WriteJSON writes the leaderboard as a JSON array. The score of a failed run
+// is null.
+func (lb Leaderboard) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(lb.entries()); err != nil {
+		return fmt.Errorf("encode leaderboard: %w", err)
+	}
+
+	return nil
+}
+
+// WriteCSV writes the leaderboard as CSV with a header line. The score of a
+// failed run is empty.
+func (lb Leaderboard) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{
+		"rank", "run", "params", "model", "approximation", "vector", "window",
+		"threshold", "frequency", "negative", "epoch", "rate", "minN", "maxN",
+		"seconds", "score", "error",
+	})
+
+	for _, e := range lb.entries() {
+		var score string
+		if e.Score != nil {
+			score = strconv.FormatFloat(*e.Score, 'f', 6, 64)
+		}
+
+		cw.Write([]string{
+			strconv.Itoa(e.Rank),
+			strconv.Itoa(e.Run),
+			e.Params,
+			e.Model,
+			e.Approximation,
+			strconv.Itoa(e.Vector),
+			strconv.Itoa(e.Window),
+			strconv.FormatFloat(e.Threshold, 'g', -1, 64),
+			strconv.Itoa(e.Frequency),
+			strconv.Itoa(e.Negative),
+			strconv.Itoa(e.Epoch),
+			strconv.FormatFloat(e.Rate, 'g', -1, 64),
+			strconv.Itoa(e.MinN),
+			strconv.Itoa(e.MaxN),
+			strconv.FormatFloat(e.Seconds, 'f', 3, 64),
+			score,
+			e.Error,
+		})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write leaderboard: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,374 @@
+// Package sweep provides support for searching the word2vec settings that
+// work best for a corpus. Every candidate configuration of a grid or random
+// search is trained on the corpus and scored with an evaluator, and the runs
+// are ranked on a leaderboard that can be written as CSV or JSON.
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/ai-training/foundation/word2vec"
+)
+
+// Value represents one of the values a setting is tried with. The label names
+// the value on the leaderboard.
+type Value struct {
+	Label string
+	Apply func(config *word2vec.Config)
+}
+
+// Param represents a setting of the word2vec configuration and the values to
+// try it with.
+type Param struct {
+	Name   string
+	Values []Value
+}
+
+// Int builds a parameter for an integer setting.
+func Int(name string, set func(config *word2vec.Config, v int), values ...int) Param {
+	p := Param{Name: name}
+	for _, v := range values {
+		p.Values = append(p.Values, Value{
+			Label: strconv.Itoa(v),
+			Apply: func(config *word2vec.Config) { set(config, v) },
+		})
+	}
+	return p
+}
+
+// Float builds a parameter for a floating point setting.
+func Float(name string, set func(config *word2vec.Config, v float64), values ...float64) Param {
+	p := Param{Name: name}
+	for _, v := range values {
+		p.Values = append(p.Values, Value{
+			Label: strconv.FormatFloat(v, 'g', -1, 64),
+			Apply: func(config *word2vec.Config) { set(config, v) },
+		})
+	}
+	return p
+}
+
+// =============================================================================
+
+// Model tries the learning models, "cbow" and "skip-gram".
+func Model(models ...string) (Param, error) {
+	p := Param{Name: "model"}
+	for _, model := range models {
+		var apply func(config *word2vec.Config)
+
+		switch model {
+		case "cbow":
+			apply = func(config *word2vec.Config) {
+				config.UseCBOW = true
+				config.UseSkipGram = false
+			}
+		case "skip-gram":
+			apply = func(config *word2vec.Config) {
+				config.UseCBOW = false
+				config.UseSkipGram = true
+			}
+		default:
+			return Param{}, fmt.Errorf("unknown model %q", model)
+		}
+
+		p.Values = append(p.Values, Value{Label: model, Apply: apply})
+	}
+	return p, nil
+}
+
+// Approximation tries the softmax approximations, "ns" for negative
+// sampling and "hs" for hierarchical softmax.
+func Approximation(approximations ...string) (Param, error) {
+	p := Param{Name: "approximation"}
+	for _, approximation := range approximations {
+		var apply func(config *word2vec.Config)
+
+		switch approximation {
+		case "ns":
+			apply = func(config *word2vec.Config) {
+				config.UseNegativeSampling = true
+				config.UseHierarchicalSoftMax = false
+			}
+		case "hs":
+			apply = func(config *word2vec.Config) {
+				config.UseNegativeSampling = false
+				config.UseHierarchicalSoftMax = true
+			}
+		default:
+			return Param{}, fmt.Errorf("unknown approximation %q", approximation)
+		}
+
+		p.Values = append(p.Values, Value{Label: approximation, Apply: apply})
+	}
+	return p, nil
+}
+
+// Vector tries the vector sizes.
+func Vector(values ...int) Param {
+	return Int("vector", func(config *word2vec.Config, v int) { config.Vector.Vector = v }, values...)
+}
+
+// Window tries the window sizes.
+func Window(values ...int) Param {
+	return Int("window", func(config *word2vec.Config, v int) { config.Vector.Window = v }, values...)
+}
+
+// Threshold tries the down-sampling thresholds.
+func Threshold(values ...float64) Param {
+	return Float("threshold", func(config *word2vec.Config, v float64) { config.Vector.Threshold = v }, values...)
+}
+
+// Frequency tries the min word frequencies.
+func Frequency(values ...int) Param {
+	return Int("frequency", func(config *word2vec.Config, v int) { config.Vector.Frequency = v }, values...)
+}
+
+// Negative tries the numbers of negative examples.
+func Negative(values ...int) Param {
+	return Int("negative", func(config *word2vec.Config, v int) { config.SizeNegativeSampling = v }, values...)
+}
+
+// Epoch tries the numbers of training runs.
+func Epoch(values ...int) Param {
+	return Int("epoch", func(config *word2vec.Config, v int) { config.Learning.Epoch = v }, values...)
+}
+
+// Rate tries the starting learning rates.
+func Rate(values ...float64) Param {
+	return Float("rate", func(config *word2vec.Config, v float64) { config.Learning.Rate = v }, values...)
+}
+
+// =============================================================================
+
+// Setting represents the value a candidate takes for a parameter.
+type Setting struct {
+	Param string
+	Value Value
+}
+
+// Candidate represents one configuration to train, as the settings applied to
+// the base configuration.
+type Candidate []Setting
+
+// String returns the settings as "name=label" pairs.
+func (c Candidate) String() string {
+	pairs := make([]string, len(c))
+	for i, s := range c {
+		pairs[i] = s.Param + "=" + s.Value.Label
+	}
+	return strings.Join(pairs, " ")
+}
+
+// apply returns the base configuration with the settings applied.
+func (c Candidate) apply(base word2vec.Config) word2vec.Config {
+	for _, s := range c {
+		s.Value.Apply(&base)
+	}
+	return base
+}
+
+// Grid returns every combination of the parameter values.
+func Grid(params ...Param) []Candidate {
+	candidates := []Candidate{nil}
+
+	for _, p := range params {
+		if len(p.Values) == 0 {
+			continue
+		}
+
+		next := make([]Candidate, 0, len(candidates)*len(p.Values))
+		for _, c := range candidates {
+			for _, v := range p.Values {
+				next = append(next, append(c[:len(c):len(c)], Setting{Param: p.Name, Value: v}))
+			}
+		}
+		candidates = next
+	}
+
+	return candidates
+}
+
+// Random returns n different combinations of the parameter values picked at
+// random, or every combination when there are fewer than n.
+func Random(n int, params ...Param) []Candidate {
+	total := 1
+	for _, p := range params {
+		if len(p.Values) > 0 {
+			total *= len(p.Values)
+		}
+		if total > n {
+			break
+		}
+	}
+
+	if total <= n {
+		grid := Grid(params...)
+		rand.Shuffle(len(grid), func(i, j int) {
+			grid[i], grid[j] = grid[j], grid[i]
+		})
+		return grid
+	}
+
+	seen := make(map[string]struct{}, n)
+	candidates := make([]Candidate, 0, n)
+
+	for len(candidates) < n {
+		var c Candidate
+		var key strings.Builder
+
+		for _, p := range params {
+			if len(p.Values) > 0 {
+				i := rand.IntN(len(p.Values))
+				c = append(c, Setting{Param: p.Name, Value: p.Values[i]})
+				fmt.Fprintf(&key, "%d,", i)
+			}
+		}
+
+		if _, exists := seen[key.String()]; exists {
+			continue
+		}
+		seen[key.String()] = struct{}{}
+
+		candidates = append(candidates, c)
+	}
+
+	return candidates
+}
+
+// =============================================================================
+
+// Evaluator scores a trained model, where a higher score is better. The
+// config is the one the model was trained with.
+type Evaluator func(ctx context.Context, model *word2vec.Model, config word2vec.Config) (float64, error)
+
+// Config defines the settings of a sweep.
+type Config struct {
+	// Base represents the configuration the candidate settings are applied
+	// to. Its corpus is used for every run and its output files are ignored.
+	Base word2vec.Config
+
+	// Candidates represents the configurations to train, see Grid and Random.
+	Candidates []Candidate
+
+	// Evaluator represents the scoring of the trained models.
+	Evaluator Evaluator
+
+	// Dir represents the directory the models are written to, one file per
+	// run. When it is empty, the models are written to a temporary directory
+	// that is removed once the sweep is over.
+	Dir string
+
+	// OnResult is called with the result of every run as soon as it is
+	// scored, to report the progress of a long sweep.
+	OnResult func(Result)
+}
+
+// Run trains and scores every candidate in turn and returns the leaderboard.
+// A run that fails to train or to score is kept on the leaderboard with its
+// error and the sweep goes on. Cancelling the context stops the sweep and the
+// leaderboard of the finished runs is returned with the context error.
+func Run(ctx context.Context, config Config) (Leaderboard, error) {
+	switch {
+	case config.Evaluator == nil:
+		return nil, errors.New("no evaluator provided")
+	case len(config.Candidates) == 0:
+		return nil, errors.New("no candidates provided")
+	}
+
+	// The corpus is read once per run, so a reader can not be shared.
+	if config.Base.Corpus.InputFile == "" {
+		return nil, errors.New("no input file provided")
+	}
+
+	dir := config.Dir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "sweep-")
+		if err != nil {
+			return nil, fmt.Errorf("create model dir: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+
+		dir = tmp
+	}
+
+	var lb Leaderboard
+
+	for i, c := range config.Candidates {
+		result := run(ctx, config, dir, i+1, c)
+
+		if err := ctx.Err(); err != nil {
+			lb.sort()
+			return lb, err
+		}
+
+		lb = append(lb, result)
+
+		if config.OnResult != nil {
+			config.OnResult(result)
+		}
+	}
+
+	lb.sort()
+
+	return lb, nil
+}
+
+func run(ctx context.Context, config Config, dir string, id int, c Candidate) Result {
+	w2vConfig := c.apply(config.Base)
+	w2vConfig.Output = filepath.Join(dir, fmt.Sprintf("run-%03d.model", id))
+	w2vConfig.OutputVocabulary = ""
+	w2vConfig.OutputSubwords = ""
+	w2vConfig.OutputWeights = ""
+
+	// Subwords are scored along with the model, as they compose the vectors
+	// of the benchmark words out of the vocabulary.
+	if w2vConfig.Subword.MaxN > 0 {
+		w2vConfig.OutputSubwords = filepath.Join(dir, fmt.Sprintf("run-%03d.subwords", id))
+	}
+
+	result := Result{
+		Run:       id,
+		Candidate: c,
+		Config:    w2vConfig,
+		Score:     math.NaN(),
+	}
+
+	start := time.Now()
+	if err := word2vec.Train(ctx, w2vConfig); err != nil {
+		result.Err = fmt.Errorf("train: %w", err)
+		return result
+	}
+	result.Duration = time.Since(start)
+
+	model, err := word2vec.Load(w2vConfig.Output, w2vConfig.Vector.Vector)
+	if err != nil {
+		result.Err = fmt.Errorf("load: %w", err)
+		return result
+	}
+	defer model.Close()
+
+	if w2vConfig.OutputSubwords != "" {
+		if err := model.LoadSubwords(w2vConfig.OutputSubwords); err != nil {
+			result.Err = fmt.Errorf("load: %w", err)
+			return result
+		}
+	}
+
+	score, err := config.Evaluator(ctx, &model, w2vConfig)
+	if err != nil {
+		result.Err = fmt.Errorf("evaluate: %w", err)
+		return result
+	}
+	result.Score = score
+
+	return result
+}
@@ -0,0 +1,116 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// Sentence represents a sentence of a text.
+type Sentence struct {
+	// Text is the text of the sentence without the surrounding spaces. It is
+	// the source text text[Start:End].
+	Text string
+
+	// Start and End are the byte offsets of the sentence in the source text.
+	Start int
+	End   int
+
+	// RuneStart and RuneEnd are the rune offsets of the sentence in the
+	// source text.
+	RuneStart int
+	RuneEnd   int
+}
+
+// abbreviations lists the common abbreviations ending with a period that
+// the UAX #29 rules take as the end of a sentence when a capitalized word
+// follows, like in "Dr. Smith".
+var abbreviations = map[string]struct{}{
+	"dr": {}, "mr": {}, "mrs": {}, "ms": {}, "prof": {}, "sr": {}, "jr": {},
+	"st": {}, "mt": {}, "vs": {}, "etc": {}, "e.g": {}, "i.e": {}, "cf": {},
+	"vol": {}, "fig": {}, "inc": {}, "ltd": {}, "corp": {}, "sra": {},
+	"dra": {}, "av": {}, "pág": {}, "núm": {},
+}
+
+// Sentences splits the text into sentences following the UAX #29 sentence
+// boundaries. Line breaks end a sentence, so every line of a file is at least
+// one sentence, and blank sentences are skipped. A sentence ending with a
+// common abbreviation is joined with the next one on the same line.
+func Sentences(text string) []Sentence {
+	var sentences []Sentence
+	var pos, runes int
+
+	rest := text
+	state := -1
+	open := -1
+
+	for len(rest) > 0 {
+		var segment string
+		segment, rest, state = uniseg.FirstSentenceInString(rest, state)
+
+		start := len(text) - len(rest) - len(segment)
+		trimmed := strings.TrimLeftFunc(segment, unicode.IsSpace)
+		start += len(segment) - len(trimmed)
+		end := start + len(strings.TrimRightFunc(trimmed, unicode.IsSpace))
+
+		if start == end {
+			continue
+		}
+
+		if open >= 0 {
+			start = open
+			open = -1
+		}
+
+		if len(rest) > 0 && endsWithAbbreviation(text[start:end]) && !strings.Contains(text[end:len(text)-len(rest)], "\n") {
+			open = start
+			continue
+		}
+
+		sentences = append(sentences, sentence(text, start, end, &pos, &runes))
+	}
+
+	if open >= 0 {
+		end := len(strings.TrimRightFunc(text, unicode.IsSpace))
+		sentences = append(sentences, sentence(text, open, end, &pos, &runes))
+	}
+
+	return sentences
+}
+
+// sentence constructs the sentence of text[start:end], keeping the rune
+// offset in step with the byte offset.
+func sentence(text string, start int, end int, pos *int, runes *int) Sentence {
+	*runes += utf8.RuneCountInString(text[*pos:start])
+	size := utf8.RuneCountInString(text[start:end])
+
+	s := Sentence{
+		Text:      text[start:end],
+		Start:     start,
+		End:       end,
+		RuneStart: *runes,
+		RuneEnd:   *runes + size,
+	}
+
+	*runes += size
+	*pos = end
+
+	return s
+}
+
+// endsWithAbbreviation reports whether the last word of the sentence is an
+// abbreviation followed by its period.
+func endsWithAbbreviation(s string) bool {
+	if !strings.HasSuffix(s, ".") {
+		return false
+	}
+	s = s[:len(s)-1]
+
+	word := s[strings.LastIndexFunc(s, unicode.IsSpace)+1:]
+	word = strings.TrimLeft(word, "([\"'")
+
+	_, exists := abbreviations[strings.ToLower(word)]
+	return exists
+}
@@ -0,0 +1,325 @@
+// Package tokenizer provides support for splitting text into words and
+// sentences following the Unicode text segmentation rules of UAX #29:
+// https://unicode.org/reports/tr29/
+//
+// On top of the Unicode rules, URLs and email addresses are kept as single
+// tokens and every token is classified as a word, number, identifier, URL or
+// email. Every token and sentence carries its byte and rune offsets in the
+// source text, so results can be mapped back to the original document.
+package tokenizer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// Kind represents the kind of a token.
+type Kind int
+
+// Set of token kinds.
+const (
+	// Word is a sequence of letters, including contractions like "don't" and
+	// elisions like "l'homme". Hyphenated words are split at the hyphen.
+	Word Kind = iota
+
+	// Number is a sequence of digits, with optional separators like in
+	// "1,234.56".
+	Number
+
+	// Identifier is a code identifier or a mix of letters and digits, like
+	// "snake_case", "camelCase", "http.Handler" or "v1.2".
+	Identifier
+
+	// URL is a web address, like "https://ardanlabs.com/training".
+	URL
+
+	// Email is an email address.
+	Email
+
+	// Punct is a punctuation mark or symbol. They are only produced when the
+	// configuration asks for them.
+	Punct
+)
+
+var kinds = [...]string{"word", "number", "identifier", "url", "email", "punct"}
+
+// String implements the fmt.Stringer interface.
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kinds) {
+		return "unknown"
+	}
+	return kinds[k]
+}
+
+// Token represents a token of a text.
+type Token struct {
+	// Text is the text of the token, after the normalizations of the
+	// configuration. The source text is text[Start:End].
+	Text string
+	Kind Kind
+
+	// Start and End are the byte offsets of the token in the source text.
+	Start int
+	End   int
+
+	// RuneStart and RuneEnd are the rune offsets of the token in the source
+	// text.
+	RuneStart int
+	RuneEnd   int
+}
+
+// =============================================================================
+
+// Config defines the settings of the tokenizer.
+type Config struct {
+	// Lowercase represents lowercasing the text of the tokens, except for
+	// URLs, whose path is case sensitive.
+	// Ex: true
+	Lowercase bool
+
+	// Apostrophes represents replacing the typographic apostrophes of the
+	// tokens, like in "don’t", with ASCII ones, so both spellings of a
+	// contraction match.
+	// Ex: true
+	Apostrophes bool
+
+	// Punctuation represents producing tokens for the punctuation marks and
+	// symbols, which are skipped otherwise. Spaces are always skipped.
+	// Ex: false
+	Punctuation bool
+}
+
+// NewConfigDefault defines a set of default configuration options.
+func NewConfigDefault() Config {
+	return Config{
+		Lowercase:   true,
+		Apostrophes: true,
+		Punctuation: false,
+	}
+}
+
+// Tokenizer splits text into tokens. It is safe for concurrent use.
+type Tokenizer struct {
+	config Config
+}
+
+// New constructs a tokenizer with the specified configuration.
+func New(config Config) *Tokenizer {
+	return &Tokenizer{
+		config: config,
+	}
+}
+
+var std = New(NewConfigDefault())
+
+// Tokenize splits the text into tokens with the default configuration.
+func Tokenize(text string) []Token {
+	return std.Tokenize(text)
+}
+
+// Words returns the text of the tokens of the text with the default
+// configuration.
+func Words(text string) []string {
+	return std.Words(text)
+}
+
+// Tokenize splits the text into tokens.
+func (t *Tokenizer) Tokenize(text string) []Token {
+	var tokens []Token
+	var pos, runes int
+
+	// emit appends the token of text[start:end], keeping the rune offset in
+	// step with the byte offset.
+	emit := func(start int, end int, kind Kind) {
+		runes += utf8.RuneCountInString(text[pos:start])
+		size := utf8.RuneCountInString(text[start:end])
+
+		tokens = append(tokens, Token{
+			Text:      t.normalize(text[start:end], kind),
+			Kind:      kind,
+			Start:     start,
+			End:       end,
+			RuneStart: runes,
+			RuneEnd:   runes + size,
+		})
+
+		runes += size
+		pos = end
+	}
+
+	// URLs and emails are found first, since the Unicode rules split them at
+	// their punctuation. The text between them is segmented into words.
+	var offset int
+	for _, span := range links(text) {
+		t.segment(text, offset, span.start, emit)
+		emit(span.start, span.end, span.kind)
+		offset = span.end
+	}
+	t.segment(text, offset, len(text), emit)
+
+	return tokens
+}
+
+// Words returns the text of the tokens of the text.
+func (t *Tokenizer) Words(text string) []string {
+	tokens := t.Tokenize(text)
+
+	words := make([]string, len(tokens))
+	for i, tkn := range tokens {
+		words[i] = tkn.Text
+	}
+
+	return words
+}
+
+// segment emits the tokens of text[start:end] found by the UAX #29 word
+// boundaries.
+func (t *Tokenizer) segment(text string, start int, end int, emit func(start int, end int, kind Kind)) {
+	rest := text[start:end]
+	state := -1
+
+	for len(rest) > 0 {
+		var word string
+		word, rest, state = uniseg.FirstWordInString(rest, state)
+
+		pos := end - len(rest) - len(word)
+
+		kind, ok := classify(word)
+		if ok && (kind != Punct || t.config.Punctuation) {
+			emit(pos, pos+len(word), kind)
+		}
+	}
+}
+
+func (t *Tokenizer) normalize(text string, kind Kind) string {
+	if t.config.Lowercase && kind != URL {
+		text = strings.ToLower(text)
+	}
+
+	if t.config.Apostrophes && kind == Word {
+		text = strings.ReplaceAll(text, "’", "'")
+	}
+
+	return text
+}
+
+// =============================================================================
+
+// classify returns the kind of a UAX #29 segment and whether it is a token,
+// rather than spaces.
+func classify(word string) (Kind, bool) {
+	var letters, digits, joiners, dots int
+	var camel bool
+	prev := rune(-1)
+
+	for _, r := range word {
+		switch {
+		case unicode.IsLetter(r):
+			letters++
+			if unicode.IsUpper(r) && prev >= 0 && unicode.IsLower(prev) {
+				camel = true
+			}
+
+		case unicode.IsDigit(r):
+			digits++
+
+		case r == '_':
+			joiners++
+
+		case r == '.':
+			dots++
+		}
+		prev = r
+	}
+
+	switch {
+	case letters == 0 && digits == 0:
+		return Punct, strings.TrimSpace(word) != ""
+
+	case letters == 0 && joiners == 0:
+		return Number, true
+
+	case joiners > 0, digits > 0, camel, dots > 0:
+		return Identifier, true
+	}
+
+	return Word, true
+}
+
+// =============================================================================
+
+// link represents the span of a URL or email address in a text.
+type link struct {
+	start int
+	end   int
+	kind  Kind
+}
+
+var (
+	urlPattern   = regexp.MustCompile(`(?i)\b(?:(?:https?|ftp)://|www\.)[^\s<>"'` + "`" + `]+`)
+	emailPattern = regexp.MustCompile(`[\pL\pN._%+-]+@[\pL\pN-]+(?:\.[\pL\pN-]+)*\.\pL{2,}`)
+)
+
+// links returns the spans of the URLs and email addresses of the text, in
+// order and without overlaps.
+func links(text string) []link {
+	var spans []link
+
+	for _, m := range urlPattern.FindAllStringIndex(text, -1) {
+		end := m[0] + trimURL(text[m[0]:m[1]])
+		spans = append(spans, link{start: m[0], end: end, kind: URL})
+	}
+
+	for _, m := range emailPattern.FindAllStringIndex(text, -1) {
+		var overlaps bool
+		for _, s := range spans {
+			if m[0] < s.end && s.start < m[1] {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+
+		// Insert the email in order of the URLs.
+		i := len(spans)
+		for i > 0 && spans[i-1].start > m[0] {
+			i--
+		}
+		spans = append(spans, link{})
+		copy(spans[i+1:], spans[i:])
+		spans[i] = link{start: m[0], end: m[1], kind: Email}
+	}
+
+	return spans
+}
+
+// trimURL returns the length of the URL without the trailing punctuation of
+// the sentence it is part of. A closing parenthesis is kept when the URL
+// opens one, like in Wikipedia links.
+func trimURL(url string) int {
+	for len(url) > 0 {
+		last := url[len(url)-1]
+
+		switch last {
+		case '.', ',', ';', ':', '!', '?', '\'', '"', ']', '}', '>':
+			url = url[:len(url)-1]
+			continue
+
+		case ')':
+			if strings.Count(url, "(") < strings.Count(url, ")") {
+				url = url[:len(url)-1]
+				continue
+			}
+		}
+
+		break
+	}
+
+	return len(url)
+}
@@ -62,6 +62,37 @@ func CosineSimilarity(x, y []float32) float32 {
 	return float32(sum / (math.Sqrt(s1) * math.Sqrt(s2)))
 }
 
+// Normalize scales the vector to unit length in place. It reports false for
+// a zero vector, which is left as is.
+func Normalize(vec []float32) bool {
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+
+	if norm == 0 {
+		return false
+	}
+
+	norm = math.Sqrt(norm)
+	for i := range vec {
+		vec[i] = float32(float64(vec[i]) / norm)
+	}
+
+	return true
+}
+
+// Dot calculates the dot product of two vectors, which is their cosine
+// similarity when both are normalized.
+func Dot(x, y []float32) float64 {
+	var sum float64
+	for i := range x {
+		sum += float64(x[i]) * float64(y[i])
+	}
+
+	return sum
+}
+
 // =============================================================================
 
 const (
@@ -0,0 +1,355 @@
+// Package weat provides support for measuring the associations a model of
+// word embeddings learned from its training data, using the Word Embedding
+// Association Test (WEAT): https://arxiv.org/abs/1608.07187
+//
+// A test compares two sets of target words, like male and female names, with
+// two sets of attribute words, like career and family words. The effect size
+// tells how much more the first targets are associated with the first
+// attributes than the second targets are, in standard deviations. The p-value
+// of a permutation test tells how likely the same association is to show up
+// by chance with the target words shuffled between the two sets.
+package weat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"sort"
+
+	"github.com/ardanlabs/ai-training/foundation/vector"
+)
+
+// Model represents the word vectors to test. It is satisfied by the word2vec
+// and glove models, and by any other source of vectors for single words.
+type Model interface {
+	VectorOf(word string, vector []float32) error
+}
+
+// WordSet represents a named set of words.
+type WordSet struct {
+	Name  string   `json:"name"`
+	Words []string `json:"words"`
+}
+
+// Test represents an association test between the target sets X and Y and
+// the attribute sets A and B.
+type Test struct {
+	Name       string  `json:"name"`
+	TargetX    WordSet `json:"targetX"`
+	TargetY    WordSet `json:"targetY"`
+	AttributeA WordSet `json:"attributeA"`
+	AttributeB WordSet `json:"attributeB"`
+}
+
+// Words returns every word of the test sets.
+func (t Test) Words() []string {
+	var words []string
+	for _, set := range []WordSet{t.TargetX, t.TargetY, t.AttributeA, t.AttributeB} {
+		words = append(words, set.Words...)
+	}
+	return words
+}
+
+// ReadTests reads the tests from a JSON file holding an array of tests:
+//
+//	[
+//	  {
+//	    "name": "career vs family",
+//	    "targetX": {"name": "male", "words": ["john", "paul"]},
+//	    "targetY": {"name": "female", "words": ["amy", "joan"]},
+//	    "attributeA": {"name": "career", "words": ["salary", "office"]},
+//	    "attributeB": {"name": "family", "words": ["home", "parents"]}
+//	  }
+//	]
+func ReadTests(fileTests string) ([]Test, error) {
+	data, err := os.ReadFile(fileTests)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	var tests []Test
+	if err := json.Unmarshal(data, &tests); err != nil {
+		return nil, fmt.Errorf("decode tests: %w", err)
+	}
+
+	if len(tests) == 0 {
+		return nil, errors.New("no tests found")
+	}
+
+	return tests, nil
+}
+
+// =============================================================================
+
+// Config defines the settings of the tests.
+type Config struct {
+	// Vector represents the number of data points of the model vectors.
+	// Ex: 300
+	Vector int
+
+	// Permutations represents the max number of partitions of the target
+	// words the p-value is computed from. When the targets have fewer
+	// partitions, all of them are used and the p-value is exact.
+	// Ex: 10000
+	Permutations int
+}
+
+// NewConfigDefault defines a set of default configuration options.
+func NewConfigDefault() Config {
+	return Config{
+		Vector:       300,
+		Permutations: 10000,
+	}
+}
+
+// Result represents the outcome of a test.
+type Result struct {
+	// Test is the name of the test.
+	Test string
+
+	// Statistic is the sum of the associations of the X targets minus the
+	// sum of the associations of the Y targets.
+	Statistic float64
+
+	// EffectSize is the difference of the mean associations of the X and Y
+	// targets over their standard deviation. It ranges from -2 to 2 and the
+	// sign tells which targets lean towards the A attributes.
+	EffectSize float64
+
+	// PValue is the one-sided probability that a random partition of the
+	// targets has a statistic at least as large.
+	PValue float64
+
+	// Permutations is the number of partitions the p-value is computed from.
+	Permutations int
+
+	// Exact reports whether all the partitions were used.
+	Exact bool
+
+	// X, Y, A and B are the number of words of every set the model has
+	// vectors for.
+	X, Y, A, B int
+
+	// OOV lists the words left out of the test as the model has no vector
+	// for them, in alphabetical order.
+	OOV []string
+}
+
+// Run performs the test against the model. Words the model has no vector
+// for are left out of the test.
+func Run(m Model, test Test, config Config) (Result, error) {
+	switch {
+	case config.Vector <= 0:
+		return Result{}, errors.New("vector size must be greater than zero")
+	case config.Permutations <= 0:
+		return Result{}, errors.New("permutations must be greater than zero")
+	}
+
+	oov := make(map[string]struct{})
+
+	vectors := func(set WordSet) [][]float32 {
+		var vecs [][]float32
+		for _, word := range set.Words {
+			vec := make([]float32, config.Vector)
+			if err := m.VectorOf(word, vec); err != nil || !vector.Normalize(vec) {
+				oov[word] = struct{}{}
+				continue
+			}
+			vecs = append(vecs, vec)
+		}
+		return vecs
+	}
+
+	x := vectors(test.TargetX)
+	y := vectors(test.TargetY)
+	a := vectors(test.AttributeA)
+	b := vectors(test.AttributeB)
+
+	result := Result{
+		Test: test.Name,
+		X:    len(x),
+		Y:    len(y),
+		A:    len(a),
+		B:    len(b),
+		OOV:  make([]string, 0, len(oov)),
+	}
+
+	for word := range oov {
+		result.OOV = append(result.OOV, word)
+	}
+	sort.Strings(result.OOV)
+
+	for _, set := range []struct {
+		name  string
+		words int
+	}{
+		{test.TargetX.Name, len(x)},
+		{test.TargetY.Name, len(y)},
+		{test.AttributeA.Name, len(a)},
+		{test.AttributeB.Name, len(b)},
+	} {
+		if set.words == 0 {
+			return result, fmt.Errorf("no vectors found for the words of set %q", set.name)
+		}
+	}
+
+	// The association of every target word with the attributes is all the
+	// test needs, the partitions only regroup them.
+	targets := append(x, y...)
+	s := make([]float64, len(targets))
+	for i, w := range targets {
+		s[i] = association(w, a, b)
+	}
+
+	var sumX, sumY float64
+	for _, v := range s[:len(x)] {
+		sumX += v
+	}
+	for _, v := range s[len(x):] {
+		sumY += v
+	}
+
+	result.Statistic = sumX - sumY
+
+	if std := stdDev(s); std > 0 {
+		result.EffectSize = (sumX/float64(len(x)) - sumY/float64(len(y))) / std
+	}
+
+	result.PValue, result.Permutations, result.Exact = pValue(s, len(x), result.Statistic, config.Permutations)
+
+	return result, nil
+}
+
+// =============================================================================
+
+// association returns the mean similarity of the word with the A attributes
+// minus its mean similarity with the B attributes.
+func association(w []float32, a [][]float32, b [][]float32) float64 {
+	var sa, sb float64
+	for _, v := range a {
+		sa += vector.Dot(w, v)
+	}
+	for _, v := range b {
+		sb += vector.Dot(w, v)
+	}
+	return sa/float64(len(a)) - sb/float64(len(b))
+}
+
+// pValue computes the share of the partitions of the associations into nx
+// and len(s)-nx values whose statistic is at least the observed one. All the
+// partitions are enumerated when there are at most limit of them, otherwise
+// limit random partitions are drawn and the observed partition is counted in.
+func pValue(s []float64, nx int, observed float64, limit int) (float64, int, bool) {
+	var total float64
+	for _, v := range s {
+		total += v
+	}
+
+	// The statistic of a partition is sum(X) - sum(Y) = 2*sum(X) - total.
+	statistic := func(sumX float64) float64 {
+		return 2*sumX - total
+	}
+
+	// Sums are compared with a tolerance, so a partition equal to the
+	// observed one is not missed due to rounding.
+	tolerance := 1e-9 * math.Max(1, math.Abs(observed))
+
+	if partitions := binomial(len(s), nx); partitions > 0 && partitions <= float64(limit) {
+		var count, n int
+		combinations(len(s), nx, func(idx []int) {
+			var sumX float64
+			for _, i := range idx {
+				sumX += s[i]
+			}
+			if statistic(sumX) >= observed-tolerance {
+				count++
+			}
+			n++
+		})
+		return float64(count) / float64(n), n, true
+	}
+
+	perm := make([]int, len(s))
+	for i := range perm {
+		perm[i] = i
+	}
+
+	var count int
+	for n := 0; n < limit; n++ {
+		rand.Shuffle(len(perm), func(i, j int) {
+			perm[i], perm[j] = perm[j], perm[i]
+		})
+
+		var sumX float64
+		for _, i := range perm[:nx] {
+			sumX += s[i]
+		}
+		if statistic(sumX) >= observed-tolerance {
+			count++
+		}
+	}
+
+	return float64(count+1) / float64(limit+1), limit, false
+}
+
+// combinations calls fn with the indexes of every k-combination of n items.
+// The idx slice is reused between calls.
+func combinations(n int, k int, fn func(idx []int)) {
+	idx := make([]int, k)
+	for i := range idx {
+		idx[i] = i
+	}
+
+	for {
+		fn(idx)
+
+		i := k - 1
+		for i >= 0 && idx[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return
+		}
+
+		idx[i]++
+		for j := i + 1; j < k; j++ {
+			idx[j] = idx[j-1] + 1
+		}
+	}
+}
+
+// binomial returns n choose k as a float, so large values do not overflow.
+func binomial(n int, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r = r * float64(n-k+i) / float64(i)
+	}
+	return math.Round(r)
+}
+
+// stdDev returns the sample standard deviation of the values.
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+
+	return math.Sqrt(sum / float64(len(values)-1))
+}
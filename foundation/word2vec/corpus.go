@@ -0,0 +1,65 @@
+//
+// Copyright (C) 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/word2vec
+//
+
+package word2vec
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// Sentences returns a reader over the sentences received from the channel,
+// one sentence per line, to be used as ConfigCorpus.Input. The reader returns
+// io.EOF once the channel is closed, so the sentences can be produced by a
+// cleaning pipeline running alongside the training.
+//
+// The sentences are separated by a new line, which must be part of both the
+// Tokenizer and the Sequencer, as it is with the default configuration.
+func Sentences(sentences <-chan string) io.Reader {
+	return &sentenceReader{sentences: sentences}
+}
+
+// Tokens returns a reader over already tokenized sentences to be used as
+// ConfigCorpus.Input. Tokens are separated by a space and sentences by a new
+// line, which must be part of both the Tokenizer and the Sequencer, as it is
+// with the default configuration. Tokens holding a character of the Tokenizer,
+// like the URLs and identifiers of the tokenizer package, are split again by
+// the trainer unless the Tokenizer is reduced to " \n".
+func Tokens(sentences [][]string) io.Reader {
+	var b bytes.Buffer
+	for _, tokens := range sentences {
+		b.WriteString(strings.Join(tokens, " "))
+		b.WriteByte('\n')
+	}
+
+	return &b
+}
+
+// =============================================================================
+
+type sentenceReader struct {
+	sentences <-chan string
+	buf       []byte
+}
+
+func (r *sentenceReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		s, ok := <-r.sentences
+		if !ok {
+			return 0, io.EOF
+		}
+
+		r.buf = append(append(r.buf[:0], s...), '\n')
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
@@ -0,0 +1,161 @@
+//
+// Copyright (C) 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/word2vec
+//
+
+package word2vec
+
+/*
+#include <stdlib.h>
+#include "libw2v/include/w2v.h"
+*/
+import "C"
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// NearestDoc represents the document id and the percent of closeness.
+type NearestDoc struct {
+	ID       uint64
+	Distance float32
+}
+
+// DocIndex represents an index of document vectors by document id. The
+// vectors are calculated by the word model the index was created with. A
+// DocIndex is safe for concurrent use.
+type DocIndex struct {
+	model *Model
+	mu    sync.RWMutex
+	h     unsafe.Pointer
+}
+
+// NewDocIndex constructs an empty document index using the specified word
+// model to calculate the document vectors.
+func NewDocIndex(model *Model) *DocIndex {
+	return &DocIndex{
+		model: model,
+		h:     C.NewDocIndex(model.h),
+	}
+}
+
+// LoadDocIndex takes a document index file on disk written by Save and loads
+// it for processing with the specified word model.
+func LoadDocIndex(model *Model, fileIndex string) (*DocIndex, error) {
+	name := C.CString(fileIndex)
+	defer C.free(unsafe.Pointer(name))
+
+	h := C.LoadDocIndex(model.h, name)
+	if uintptr(h) == 0 {
+		return nil, fmt.Errorf("unable to load document index")
+	}
+
+	d := DocIndex{
+		model: model,
+		h:     h,
+	}
+
+	return &d, nil
+}
+
+// Close releases the memory held by the index.
+func (d *DocIndex) Close() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.h != nil {
+		C.FreeDocIndex(d.h)
+		d.h = nil
+	}
+}
+
+// Save writes the document index to the specified file.
+func (d *DocIndex) Save(fileIndex string) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	name := C.CString(fileIndex)
+	defer C.free(unsafe.Pointer(name))
+
+	if C.SaveDocIndex(d.h, name) == 0 {
+		return fmt.Errorf("unable to save document index")
+	}
+
+	return nil
+}
+
+// Size returns the number of documents in the index.
+func (d *DocIndex) Size() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return int(C.DocIndexSize(d.h))
+}
+
+// Add calculates the vector of the document and stores it under the
+// specified id, replacing the document previously stored under that id.
+func (d *DocIndex) Add(id uint64, doc string) error {
+	cdoc := C.CString(doc)
+	defer C.free(unsafe.Pointer(cdoc))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if C.DocIndexSet(d.h, d.model.h, C.uint64_t(id), cdoc) == 0 {
+		return errors.New("unknown tokens")
+	}
+
+	return nil
+}
+
+// Remove deletes the document stored under the specified id.
+func (d *DocIndex) Remove(id uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	C.DocIndexErase(d.h, C.uint64_t(id))
+}
+
+// Lookup returns up to k documents nearest to the query in descending order
+// of similarity.
+func (d *DocIndex) Lookup(query string, k int) ([]NearestDoc, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+
+	cq := C.CString(query)
+	defer C.free(unsafe.Pointer(cq))
+
+	type docs_t struct {
+		ids  *C.uint64_t
+		seq  *C.float
+		size C.size_t
+	}
+
+	d.mu.RLock()
+	bag := (docs_t)(C.DocIndexLookup(d.h, d.model.h, cq, C.size_t(k)))
+	d.mu.RUnlock()
+
+	if bag.ids == nil || bag.seq == nil {
+		return nil, errors.New("unknown tokens")
+	}
+	defer C.free(unsafe.Pointer(bag.ids))
+	defer C.free(unsafe.Pointer(bag.seq))
+
+	size := int(bag.size)
+	seqi := unsafe.Slice((*uint64)(unsafe.Pointer(bag.ids)), size)
+	seqd := unsafe.Slice((*float32)(bag.seq), size)
+
+	docs := make([]NearestDoc, size)
+	for i := 0; i < size; i++ {
+		docs[i].ID = seqi[i]
+		docs[i].Distance = seqd[i]
+	}
+
+	return docs, nil
+}
@@ -11,9 +11,41 @@ extern "C"
 {
 #endif
 
-  void *Train(
-      char *fileTrain,
-      char *fileStopWords,
+  struct progress_t
+  {
+    uintptr_t handle;
+    void (*vocabularyProgress)(uintptr_t handle, float percent);
+    void (*vocabularyStats)(uintptr_t handle, size_t vocWords, size_t trainWords, size_t totalWords);
+    void (*trainProgress)(uintptr_t handle, float alpha, float percent);
+  };
+
+  struct corpus_t
+  {
+    char *file;
+    const char *data;
+    size_t size;
+  };
+
+  struct words_t
+  {
+    uint64_t *freq;
+    size_t size;
+    size_t len;
+    char *buf;
+  };
+
+  struct resume_t
+  {
+    char *fileModel;
+    char *fileWeights;
+    struct words_t vocabulary;
+  };
+
+  void *New(void);
+  uint8_t Train(
+      void *fd,
+      struct corpus_t train,
+      struct corpus_t stopWords,
       char *fileModel,
       uint16_t minWordFreq,
       uint16_t vectorSize,
@@ -27,21 +59,53 @@ extern "C"
       uint8_t withSG,
       char *wordDelimiterChars,
       char *endOfSentenceChars,
-      uint8_t verbose);
+      uint8_t minN,
+      uint8_t maxN,
+      uint32_t buckets,
+      char *fileSubwords,
+      char *fileWeights,
+      struct resume_t resume,
+      struct progress_t progress);
+  void Stop(void *fd);
+  char *ErrMsg(void *fd);
   void *Load(const char *file);
   void Free(void *fd);
+  size_t VectorSize(void *fd);
+  void SetWordDelimiterChars(void *fd, const char *chars);
+  uint8_t LoadSubwords(void *fd, const char *file);
 
   float *VectorOf(void *fd, const char *word);
   float *Embedding(void *fd, const char *doc);
+  uint8_t EmbedBatch(void *fd, const char *docs, size_t n, float *vectors, size_t vectorSize, uint8_t *status);
 
   struct nearest_t
   {
     float *seq;
+    size_t size;
     size_t len;
     char *buf;
   };
 
   struct nearest_t Lookup(void *fd, const char *query, size_t k);
+  struct nearest_t LookupVector(void *fd, const float *vec, size_t size, size_t k);
+
+  struct words_t Words(void *fd);
+
+  struct docs_t
+  {
+    uint64_t *ids;
+    float *seq;
+    size_t size;
+  };
+
+  void *NewDocIndex(void *fd);
+  void *LoadDocIndex(void *fd, const char *file);
+  void FreeDocIndex(void *dd);
+  uint8_t SaveDocIndex(void *dd, const char *file);
+  size_t DocIndexSize(void *dd);
+  uint8_t DocIndexSet(void *dd, void *fd, uint64_t id, const char *doc);
+  void DocIndexErase(void *dd, uint64_t id);
+  struct docs_t DocIndexLookup(void *dd, void *fd, const char *query, size_t k);
 #ifdef __cplusplus
 }
 #endif
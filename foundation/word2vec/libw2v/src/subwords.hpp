@@ -0,0 +1,91 @@
+/**
+ * @file
+ * @brief character n-grams of a word hashed to buckets, fastText style
+ * @copyright Apache License v.2 (http://www.apache.org/licenses/LICENSE-2.0)
+*/
+#ifndef WORD2VEC_SUBWORDS_H
+#define WORD2VEC_SUBWORDS_H
+
+#include <cstdint>
+#include <string>
+#include <vector>
+
+namespace w2v {
+    /**
+     * @brief subwords class - character n-grams of a word
+     *
+     * A word is wrapped with '<' and '>' and split into n-grams of minN..maxN UTF-8 characters, each n-gram is
+     * hashed (FNV-1a) to one of the buckets. Words sharing n-grams share the vectors of their buckets, which allows
+     * to compose a vector for a word out of the vocabulary. See https://arxiv.org/abs/1607.04606
+    */
+    class subwords_t final {
+    private:
+        const uint8_t m_minN;
+        const uint8_t m_maxN;
+        const std::size_t m_buckets;
+
+    public:
+        /**
+         * Constructs a subwords object
+         * @param _minN min length of n-grams in characters
+         * @param _maxN max length of n-grams in characters
+         * @param _buckets number of hash buckets
+         */
+        subwords_t(uint8_t _minN, uint8_t _maxN, std::size_t _buckets) :
+                m_minN(_minN), m_maxN(_maxN), m_buckets(_buckets) {
+        }
+
+        /**
+         * Calculates buckets of the word n-grams
+         * @param _word word to split into n-grams
+         * @param[out] _buckets bucket of every n-gram, there can be duplicates
+         */
+        inline void operator()(const std::string &_word, std::vector<std::size_t> &_buckets) const noexcept {
+            _buckets.clear();
+            if (m_buckets == 0 || m_maxN == 0) {
+                return;
+            }
+
+            std::string word = "<" + _word + ">";
+            std::string ngram;
+            for (std::size_t i = 0; i < word.size(); ++i) {
+                if ((word[i] & 0xC0) == 0x80) {
+                    continue; // not the first byte of an UTF-8 character
+                }
+
+                ngram.clear();
+                std::size_t j = i;
+                for (std::size_t n = 1; j < word.size() && n <= m_maxN; ++n) {
+                    ngram.push_back(word[j++]);
+                    while (j < word.size() && (word[j] & 0xC0) == 0x80) {
+                        ngram.push_back(word[j++]);
+                    }
+
+                    // single '<' and '>' carry no information
+                    if (n >= m_minN && !(n == 1 && (i == 0 || j == word.size()))) {
+                        _buckets.push_back(hash(ngram) % m_buckets);
+                    }
+                }
+            }
+        }
+
+        /// @returns min length of n-grams
+        inline uint8_t minN() const noexcept { return m_minN; }
+        /// @returns max length of n-grams
+        inline uint8_t maxN() const noexcept { return m_maxN; }
+        /// @returns number of hash buckets
+        inline std::size_t buckets() const noexcept { return m_buckets; }
+
+    private:
+        static inline uint32_t hash(const std::string &_str) noexcept {
+            uint32_t h = 2166136261u;
+            for (auto const &c : _str) {
+                h = h ^ static_cast<uint32_t>(static_cast<int8_t>(c));
+                h = h * 16777619u;
+            }
+            return h;
+        }
+    };
+}
+
+#endif //WORD2VEC_SUBWORDS_H
@@ -26,7 +26,7 @@ namespace w2v {
 
         if (m_sharedData.trainSettings->sample > 0.0f) {
             m_downSampling.reset(new downSampling_t(m_sharedData.trainSettings->sample,
-                                                    m_sharedData.vocabulary->trainWords()));
+                                                    m_sharedData.vocabulary->wordsFrequency()));
         }
 
         if (m_sharedData.trainSettings->negative > 0) {
@@ -40,7 +40,7 @@ namespace w2v {
         }
 
         m_hiddenLayerErrors.reset(new std::vector<float>(m_sharedData.trainSettings->size));
-        if (!m_sharedData.trainSettings->withSG) {
+        if (!m_sharedData.trainSettings->withSG || m_sharedData.subwords) {
             m_hiddenLayerVals.reset(new std::vector<float>(m_sharedData.trainSettings->size));
         }
 
@@ -51,10 +51,10 @@ namespace w2v {
         auto startFrom = shift * _id;
         auto stopAt = (_id == m_sharedData.trainSettings->threads - 1)
                       ? (m_sharedData.fileMapper->size() - 1) : (shift * (_id + 1));
-        m_wordReader.reset(new wordReader_t<fileMapper_t>(*m_sharedData.fileMapper,
-                                                          m_sharedData.trainSettings->wordDelimiterChars,
-                                                          m_sharedData.trainSettings->endOfSentenceChars,
-                                                          startFrom, stopAt));
+        m_wordReader.reset(new wordReader_t<mapper_t>(*m_sharedData.fileMapper,
+                                                      m_sharedData.trainSettings->wordDelimiterChars,
+                                                      m_sharedData.trainSettings->endOfSentenceChars,
+                                                      startFrom, stopAt));
     }
 
     void trainThread_t::worker(std::vector<float> &_trainMatrix) noexcept {
@@ -67,6 +67,10 @@ namespace w2v {
                               * m_sharedData.vocabulary->trainWords();
             auto wordsPerAlpha = wordsPerAllThreads / 10000;
             while (!exitFlag) {
+                if (m_sharedData.stop && *m_sharedData.stop) {
+                    return; // training cancelled
+                }
+
                 // calc alpha
                 if (threadProcessedWords - prvThreadProcessedWords > wordsPerAlpha) { // next 0.01% processed
                     *m_sharedData.processedWords += threadProcessedWords - prvThreadProcessedWords;
@@ -113,7 +117,13 @@ namespace w2v {
                     sentence.push_back(wordData);
                 }
 
-                if (m_sharedData.trainSettings->withSG) {
+                if (m_sharedData.subwords) {
+                    if (m_sharedData.trainSettings->withSG) {
+                        skipGramSubwords(sentence, _trainMatrix);
+                    } else {
+                        cbowSubwords(sentence, _trainMatrix);
+                    }
+                } else if (m_sharedData.trainSettings->withSG) {
                     skipGram(sentence, _trainMatrix);
                 } else {
                     cbow(sentence, _trainMatrix);
@@ -209,6 +219,108 @@ namespace w2v {
         }
     }
 
+    inline void trainThread_t::cbowSubwords(const std::vector<const vocabulary_t::wordData_t *> &_sentence,
+                                            std::vector<float> &_trainMatrix) noexcept {
+        auto size = m_sharedData.trainSettings->size;
+        for (std::size_t i = 0; i < _sentence.size(); ++i) {
+            // hidden layers initialized with 0 values
+            std::memset(m_hiddenLayerVals->data(), 0, m_hiddenLayerVals->size() * sizeof(float));
+            std::memset(m_hiddenLayerErrors->data(), 0, m_hiddenLayerErrors->size() * sizeof(float));
+
+            auto rndShift = m_rndWindowShift(m_randomGenerator);
+            std::size_t cw = 0;
+            for (auto j = rndShift; j < m_sharedData.trainSettings->window * 2 + 1 - rndShift; ++j) {
+                if (j == m_sharedData.trainSettings->window) {
+                    continue;
+                }
+
+                auto posRndWindow = i - m_sharedData.trainSettings->window + j;
+                if (posRndWindow >= _sentence.size()) {
+                    continue;
+                }
+                for (auto const &row:(*m_sharedData.subwords)[_sentence[posRndWindow]->index]) {
+                    for (std::size_t k = 0; k < size; ++k) {
+                        (*m_hiddenLayerVals)[k] += _trainMatrix[k + row * size];
+                    }
+                    cw++;
+                }
+            }
+            if (cw == 0) {
+                continue;
+            }
+            for (std::size_t j = 0; j < size; j++) {
+                (*m_hiddenLayerVals)[j] /= cw;
+            }
+
+            if (m_sharedData.trainSettings->withHS) {
+                hierarchicalSoftmax(_sentence[i]->index, *m_hiddenLayerErrors, *m_hiddenLayerVals, 0);
+            } else {
+                negativeSampling(_sentence[i]->index, *m_hiddenLayerErrors, *m_hiddenLayerVals, 0);
+            }
+
+            // hidden -> in, words and their n-grams
+            for (auto j = rndShift; j < m_sharedData.trainSettings->window * 2 + 1 - rndShift; ++j) {
+                if (j == m_sharedData.trainSettings->window) {
+                    continue;
+                }
+
+                auto posRndWindow = i - m_sharedData.trainSettings->window + j;
+                if (posRndWindow >= _sentence.size()) {
+                    continue;
+                }
+                for (auto const &row:(*m_sharedData.subwords)[_sentence[posRndWindow]->index]) {
+                    for (std::size_t k = 0; k < size; ++k) {
+                        _trainMatrix[k + row * size] += (*m_hiddenLayerErrors)[k];
+                    }
+                }
+            }
+        }
+    }
+
+    inline void trainThread_t::skipGramSubwords(const std::vector<const vocabulary_t::wordData_t *> &_sentence,
+                                                std::vector<float> &_trainMatrix) noexcept {
+        auto size = m_sharedData.trainSettings->size;
+        for (std::size_t i = 0; i < _sentence.size(); ++i) {
+            auto rndShift = m_rndWindowShift(m_randomGenerator);
+            for (auto j = rndShift; j < m_sharedData.trainSettings->window * 2 + 1 - rndShift; ++j) {
+                if (j == m_sharedData.trainSettings->window) {
+                    continue;
+                }
+
+                auto posRndWindow = i - m_sharedData.trainSettings->window + j;
+                if (posRndWindow >= _sentence.size()) {
+                    continue;
+                }
+                // input vector is the average of the selected word and its n-gram vectors
+                auto const &rows = (*m_sharedData.subwords)[_sentence[posRndWindow]->index];
+                std::memset(m_hiddenLayerVals->data(), 0, m_hiddenLayerVals->size() * sizeof(float));
+                for (auto const &row:rows) {
+                    for (std::size_t k = 0; k < size; ++k) {
+                        (*m_hiddenLayerVals)[k] += _trainMatrix[k + row * size];
+                    }
+                }
+                for (std::size_t k = 0; k < size; ++k) {
+                    (*m_hiddenLayerVals)[k] /= rows.size();
+                }
+
+                // hidden layer initialized with 0 values
+                std::memset(m_hiddenLayerErrors->data(), 0, m_hiddenLayerErrors->size() * sizeof(float));
+
+                if (m_sharedData.trainSettings->withHS) {
+                    hierarchicalSoftmax(_sentence[i]->index, (*m_hiddenLayerErrors), *m_hiddenLayerVals, 0);
+                } else {
+                    negativeSampling(_sentence[i]->index, (*m_hiddenLayerErrors), *m_hiddenLayerVals, 0);
+                }
+
+                for (auto const &row:rows) {
+                    for (std::size_t k = 0; k < size; ++k) {
+                        _trainMatrix[k + row * size] += (*m_hiddenLayerErrors)[k];
+                    }
+                }
+            }
+        }
+    }
+
     inline void trainThread_t::hierarchicalSoftmax(std::size_t _index,
                                                    std::vector<float> &_hiddenLayer,
                                                    std::vector<float> &_trainLayer,
@@ -32,6 +32,8 @@ namespace w2v {
      *  speedup training - Hierarchical Softmax (HS) and Negative Sampling (NS).
      *  It is possible to choose any of the following algorithms combination - CBOW/HS or CBOW/NS or Skip-Gram/HS or
      *  Skip-Gram/NS.
+     *  With subwords, the input vector of a word is the average of its own vector and the vectors of its character
+     *  n-grams, so the errors are propagated back to all of them.
     */
     class trainThread_t final {
     public:
@@ -41,12 +43,15 @@ namespace w2v {
         struct sharedData_t final {
             std::shared_ptr<trainSettings_t> trainSettings; ///< trainSettings structure
             std::shared_ptr<vocabulary_t> vocabulary; ///< words data
-            std::shared_ptr<fileMapper_t> fileMapper; ///< train data file access object
+            std::shared_ptr<mapper_t> fileMapper; ///< train data access object
             std::shared_ptr<std::vector<float>> bpWeights; ///< back propagation weights
             std::shared_ptr<std::vector<float>> expTable; ///< exp(x) / (exp(x) + 1) values lookup table
             std::shared_ptr<huffmanTree_t> huffmanTree; ///< Huffman tree used by hierarchical softmax
             std::shared_ptr<std::atomic<std::size_t>> processedWords; ///< total words processed by train threads
             std::shared_ptr<std::atomic<float>> alpha; ///< current learning rate
+            std::shared_ptr<std::atomic<bool>> stop; ///< training cancellation flag
+            /// input matrix rows of every word - the word itself and its n-gram buckets, nullptr without subwords
+            std::shared_ptr<std::vector<std::vector<std::size_t>>> subwords;
             std::function<void(float, float)> progressCallback = nullptr; ///< callback with alpha and training percent
         };
 
@@ -60,7 +65,7 @@ namespace w2v {
         std::unique_ptr<nsDistribution_t> m_nsDistribution;
         std::unique_ptr<std::vector<float>> m_hiddenLayerVals;
         std::unique_ptr<std::vector<float>> m_hiddenLayerErrors;
-        std::unique_ptr<wordReader_t<fileMapper_t>> m_wordReader;
+        std::unique_ptr<wordReader_t<mapper_t>> m_wordReader;
         std::unique_ptr<std::thread> m_thread;
 
     public:
@@ -88,6 +93,10 @@ namespace w2v {
 
         inline void cbow(const std::vector<const vocabulary_t::wordData_t *> &_sentence,
                          std::vector<float> &_trainMatrix) noexcept;
+        inline void cbowSubwords(const std::vector<const vocabulary_t::wordData_t *> &_sentence,
+                                 std::vector<float> &_trainMatrix) noexcept;
+        inline void skipGramSubwords(const std::vector<const vocabulary_t::wordData_t *> &_sentence,
+                                     std::vector<float> &_trainMatrix) noexcept;
         inline void skipGram(const std::vector<const vocabulary_t::wordData_t *> &_sentence,
                              std::vector<float> &_trainMatrix) noexcept;
         inline void  hierarchicalSoftmax(std::size_t _index,
@@ -13,8 +13,9 @@
 namespace w2v {
     trainer_t::trainer_t(const std::shared_ptr<trainSettings_t> &_trainSettings,
                          const std::shared_ptr<vocabulary_t> &_vocabulary,
-                         const std::shared_ptr<fileMapper_t> &_fileMapper,
-                         std::function<void(float, float)> _progressCallback): m_threads() {
+                         const std::shared_ptr<mapper_t> &_fileMapper,
+                         const std::shared_ptr<std::atomic<bool>> &_stop,
+                         std::function<void(float, float)> _progressCallback): m_bpWeights(), m_threads() {
         trainThread_t::sharedData_t sharedData;
 
         if (!_trainSettings) {
@@ -31,8 +32,10 @@ namespace w2v {
             throw std::runtime_error("file mapper object is not initialized");
         }
         sharedData.fileMapper = _fileMapper;
+        sharedData.stop = _stop;
 
-        sharedData.bpWeights.reset(new std::vector<float>(_trainSettings->size * _vocabulary->size(), 0.0f));
+        m_bpWeights.reset(new std::vector<float>(_trainSettings->size * _vocabulary->size(), 0.0f));
+        sharedData.bpWeights = m_bpWeights;
         sharedData.expTable.reset(new std::vector<float>(_trainSettings->expTableSize));
         for (uint16_t i = 0; i < _trainSettings->expTableSize; ++i) {
             // Precompute the exp() table
@@ -58,12 +61,43 @@ namespace w2v {
 
         m_matrixSize = sharedData.trainSettings->size * sharedData.vocabulary->size();
 
+        if (_trainSettings->maxN > 0) {
+            if (_trainSettings->minN == 0 || _trainSettings->minN > _trainSettings->maxN) {
+                throw std::runtime_error("wrong character n-gram lengths");
+            }
+            if (_trainSettings->buckets == 0) {
+                throw std::runtime_error("number of n-gram buckets must be greater than zero");
+            }
+
+            // n-gram bucket vectors follow the word vectors in the matrix
+            subwords_t subwords(_trainSettings->minN, _trainSettings->maxN, _trainSettings->buckets);
+            std::vector<std::string> words;
+            _vocabulary->words(words);
+            sharedData.subwords.reset(new std::vector<std::vector<std::size_t>>(words.size()));
+            std::vector<std::size_t> buckets;
+            for (std::size_t i = 0; i < words.size(); ++i) {
+                auto &rows = (*sharedData.subwords)[i];
+                rows.push_back(i);
+                if (i == 0) {
+                    continue; // </s> has no n-grams
+                }
+                subwords(words[i], buckets);
+                for (auto const &b : buckets) {
+                    rows.push_back(words.size() + b);
+                }
+            }
+
+            m_matrixSize += sharedData.trainSettings->size * _trainSettings->buckets;
+        }
+
         for (uint8_t i = 0; i < _trainSettings->threads; ++i) {
             m_threads.emplace_back(new trainThread_t(i, sharedData));
         }
     }
 
-    void trainer_t::operator()(std::vector<float> &_trainMatrix) noexcept {
+    void trainer_t::operator()(std::vector<float> &_trainMatrix,
+                               std::vector<float> &_bpWeights,
+                               initCallback_t _initCallback) noexcept {
         // input matrix initialized with small random values
         std::random_device randomDevice;
         std::mt19937_64 randomGenerator(randomDevice());
@@ -73,6 +107,10 @@ namespace w2v {
             return rndMatrixInitializer(randomGenerator);
         });
 
+        if (_initCallback != nullptr) {
+            _initCallback(_trainMatrix, *m_bpWeights);
+        }
+
         for (auto &i:m_threads) {
             i->launch(_trainMatrix);
         }
@@ -80,5 +118,7 @@ namespace w2v {
         for (auto &i:m_threads) {
             i->join();
         }
+
+        _bpWeights.swap(*m_bpWeights);
     }
 }
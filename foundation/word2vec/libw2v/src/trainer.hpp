@@ -12,11 +12,13 @@
 #include <memory>
 #include <vector>
 #include <functional>
+#include <atomic>
 
 #include "word2vec.hpp"
 #include "wordReader.hpp"
 #include "vocabulary.hpp"
 #include "trainThread.hpp"
+#include "subwords.hpp"
 
 namespace w2v {
     /**
@@ -26,8 +28,13 @@ namespace w2v {
      * train process itself.
     */
     class trainer_t {
+    public:
+        /// type of callback function to set initial values of the train matrix and back propagation weights
+        using initCallback_t = std::function<void(std::vector<float> &, std::vector<float> &)>;
+
     private:
         std::size_t m_matrixSize = 0;
+        std::shared_ptr<std::vector<float>> m_bpWeights;
         std::vector<std::unique_ptr<trainThread_t>> m_threads;
 
     public:
@@ -35,19 +42,28 @@ namespace w2v {
          * Constructs a trainer object
          * @param _trainSettings trainSattings object
          * @param _vocabulary vocabulary object
-         * @param _fileMapper fileMapper object related to a train data set file
+         * @param _fileMapper mapper object related to a train data set
+         * @param _stop flag to stop the train threads as soon as it is set
          * @param _progressCallback callback function to be called on each new 0.01% processed train data
         */
         trainer_t(const std::shared_ptr<trainSettings_t> &_trainSettings,
                   const std::shared_ptr<vocabulary_t> &_vocabulary,
-                  const std::shared_ptr<fileMapper_t> &_fileMapper,
+                  const std::shared_ptr<mapper_t> &_fileMapper,
+                  const std::shared_ptr<std::atomic<bool>> &_stop,
                   std::function<void(float, float)> _progressCallback);
 
         /**
          * Runs training process
-         * @param[out] _trainMatrix train model matrix
+         * @param[out] _trainMatrix train model matrix, word vectors followed by n-gram bucket vectors when
+         * subwords are enabled
+         * @param[out] _bpWeights back propagation weights, output layer vectors of words for negative sampling or
+         * of Huffman tree nodes for hierarchical softmax
+         * @param _initCallback callback function called before training to override the initial small random
+         * values of the matrix and zero values of the weights, nullptr if not needed
         */
-        void operator()(std::vector<float> &_trainMatrix) noexcept;
+        void operator()(std::vector<float> &_trainMatrix,
+                        std::vector<float> &_bpWeights,
+                        initCallback_t _initCallback = nullptr) noexcept;
     };
 }
 
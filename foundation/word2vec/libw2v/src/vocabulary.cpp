@@ -10,17 +10,19 @@
 #include "wordReader.hpp"
 
 namespace w2v {
-    vocabulary_t::vocabulary_t(std::shared_ptr<fileMapper_t> &_trainWordsMapper,
-                               std::shared_ptr<fileMapper_t> &_stopWordsMapper,
+    vocabulary_t::vocabulary_t(const std::shared_ptr<mapper_t> &_trainWordsMapper,
+                               const std::shared_ptr<mapper_t> &_stopWordsMapper,
                                const std::string &_wordDelimiterChars,
                                const std::string &_endOfSentenceChars,
                                uint16_t _minFreq,
+                               const std::unordered_map<std::string, std::size_t> &_baseWords,
+                               const std::shared_ptr<std::atomic<bool>> &_stop,
                                w2vModel_t::vocabularyProgressCallback_t _progressCallback,
                                w2vModel_t::vocabularyStatsCallback_t _statsCallback) noexcept: m_words() {
         // load stop-words
         std::vector<std::string> stopWords;
-        if (_stopWordsMapper) {
-            wordReader_t<fileMapper_t> wordReader(*_stopWordsMapper, _wordDelimiterChars, _endOfSentenceChars);
+        if (_stopWordsMapper && _stopWordsMapper->size() > 0) {
+            wordReader_t<mapper_t> wordReader(*_stopWordsMapper, _wordDelimiterChars, _endOfSentenceChars);
             std::string word;
             while (wordReader.nextWord(word)) {
                 stopWords.push_back(word);
@@ -35,9 +37,12 @@ namespace w2v {
         std::unordered_map<std::string, tmpWordData_t> tmpWords;
         off_t progressOffset = 0;
         if (_trainWordsMapper) {
-            wordReader_t<fileMapper_t> wordReader(*_trainWordsMapper, _wordDelimiterChars, _endOfSentenceChars);
+            wordReader_t<mapper_t> wordReader(*_trainWordsMapper, _wordDelimiterChars, _endOfSentenceChars);
             std::string word;
             while (wordReader.nextWord(word)) {
+                if (_stop && *_stop) {
+                    break;
+                }
                 if (word.empty()) {
                     word = "</s>";
                 }
@@ -78,11 +83,25 @@ namespace w2v {
         // delimiter is the first word
         wordsFreq.emplace_back(std::pair<std::string, std::size_t>("</s>", 0LU));
         for (auto const &i:tmpWords) {
-            if (i.second.frequency >= _minFreq) {
+            auto base = _baseWords.find(i.first);
+            if (base != _baseWords.end()) {
+                wordsFreq.emplace_back(std::pair<std::string, std::size_t>(i.first,
+                                                                           i.second.frequency + base->second));
+                m_trainWords += i.second.frequency;
+            } else if (i.second.frequency >= _minFreq) {
                 wordsFreq.emplace_back(std::pair<std::string, std::size_t>(i.first, i.second.frequency));
                 m_trainWords += i.second.frequency;
             }
         }
+        // base words missing in the train data set keep their previous frequencies
+        for (auto const &i:_baseWords) {
+            if (i.first != "</s>" && tmpWords.find(i.first) == tmpWords.end()) {
+                wordsFreq.emplace_back(i);
+            }
+        }
+        for (std::size_t i = 1; i < wordsFreq.size(); ++i) {
+            m_wordsFrequency += wordsFreq[i].second;
+        }
 
         // sorting, from more frequent to less frequent, skip delimiter </s> (first word)
         if (wordsFreq.size() > 1) {
@@ -92,15 +111,10 @@ namespace w2v {
             });
             // make delimiter frequency more then the most frequent word
             wordsFreq[0].second = wordsFreq[1].second + 1;
-            // restore sentence delimiter
-            auto &i = tmpWords["</s>"];
-            i.word = "</s>";
-            i.frequency = wordsFreq[0].second;
         }
         // fill index values
         for (std::size_t i = 0; i < wordsFreq.size(); ++i) {
-            auto &w = tmpWords[wordsFreq[i].first];
-            m_words[wordsFreq[i].first] = wordData_t(i, w.frequency);
+            m_words[wordsFreq[i].first] = wordData_t(i, wordsFreq[i].second);
         }
 
         if (_statsCallback != nullptr) {
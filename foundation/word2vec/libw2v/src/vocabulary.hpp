@@ -13,6 +13,7 @@
 #include <vector>
 #include <unordered_map>
 #include <algorithm>
+#include <atomic>
 
 #include "word2vec.hpp"
 #include "mapper.hpp"
@@ -47,25 +48,31 @@ namespace w2v {
 
         std::size_t m_trainWords = 0;
         std::size_t m_totalWords = 0;
+        std::size_t m_wordsFrequency = 0;
 
         wordMap_t m_words;
 
     public:
         /**
          * Constructs a vocabulary object from the specified files and parameters
-         * @param _trainWordsMapper smart pointer to fileMapper object related to a train data set file
-         * @param _stopWordsMapper smart pointer to fileMapper object related to a file with stop-words.
+         * @param _trainWordsMapper smart pointer to mapper object related to a train data set
+         * @param _stopWordsMapper smart pointer to mapper object related to stop-words.
          * In case of unititialized pointer, _stopWordsMapper will be ignored.
          * @param _minFreq minimum word frequency to include into vocabulary
+         * @param _baseWords words of a model being trained further with their frequencies in the previous train
+         * data sets, they are always included into vocabulary and their frequencies are summed with the new ones
+         * @param _stop flag to stop parsing of the train data set as soon as it is set
          * @param _progressCallback callback function to be called on each new 0.01% processed train data
          * @param _statsCallback callback function to be called on train data loaded event to pass vocabulary size,
          * train words and total words amounts.
         */
-        vocabulary_t(std::shared_ptr<fileMapper_t> &_trainWordsMapper,
-                     std::shared_ptr<fileMapper_t> &_stopWordsMapper,
+        vocabulary_t(const std::shared_ptr<mapper_t> &_trainWordsMapper,
+                     const std::shared_ptr<mapper_t> &_stopWordsMapper,
                      const std::string &_wordDelimiterChars,
                      const std::string &_endOfSentenceChars,
                      uint16_t _minFreq,
+                     const std::unordered_map<std::string, std::size_t> &_baseWords,
+                     const std::shared_ptr<std::atomic<bool>> &_stop,
                      w2vModel_t::vocabularyProgressCallback_t _progressCallback,
                      w2vModel_t::vocabularyStatsCallback_t _statsCallback) noexcept;
 
@@ -98,6 +105,11 @@ namespace w2v {
             return m_trainWords;
         }
 
+        /// @returns sum of vocabulary word frequencies, it equals to trainWords unless there are base words
+        inline std::size_t wordsFrequency() const noexcept  {
+            return m_wordsFrequency;
+        }
+
         /**
          * Requests word frequencies
          * @param[out] _output - vector of word frequencies where vector indexes are word indexes and vector values
@@ -1,23 +1,26 @@
 #include "w2v.h"
 #include "word2vec.hpp"
+#include "mapper.hpp"
 
 #include <inttypes.h>
 #include <iostream>
 
 #include <iostream>
-#include <iomanip>
+#include <cstring>
 #include <stdexcept>
 
 class H
 {
 public:
   std::unique_ptr<w2v::w2vModel_t> model;
+  std::string wordDelimiterChars;
+  std::string errMsg;
 
   H();
   ~H();
 };
 
-H::H()
+H::H() : wordDelimiterChars(w2v::trainSettings_t().wordDelimiterChars)
 {
   model.reset(new w2v::w2vModel_t());
 }
@@ -27,9 +30,31 @@ H::~H()
   model.reset();
 }
 
-void *Train(
-    char *fileTrain,
-    char *fileStopWords,
+void *New()
+{
+  return new H();
+}
+
+// Maps the corpus to memory, it is either a file or data already in memory.
+static std::shared_ptr<w2v::mapper_t> mapper(const struct corpus_t &corpus)
+{
+  if (corpus.data != nullptr)
+  {
+    return std::make_shared<w2v::mapper_t>(corpus.data, static_cast<off_t>(corpus.size));
+  }
+
+  if (corpus.file != nullptr && corpus.file[0] != '\0')
+  {
+    return std::make_shared<w2v::fileMapper_t>(corpus.file);
+  }
+
+  return nullptr;
+}
+
+uint8_t Train(
+    void *fd,
+    struct corpus_t train,
+    struct corpus_t stopWords,
     char *fileModel,
     uint16_t minWordFreq,
     uint16_t vectorSize,
@@ -43,7 +68,13 @@ void *Train(
     uint8_t withSG,
     char *wordDelimiterChars,
     char *endOfSentenceChars,
-    uint8_t verbose)
+    uint8_t minN,
+    uint8_t maxN,
+    uint32_t buckets,
+    char *fileSubwords,
+    char *fileWeights,
+    struct resume_t resume,
+    struct progress_t progress)
 {
   w2v::trainSettings_t trainSettings;
   trainSettings.size = vectorSize;
@@ -58,93 +89,125 @@ void *Train(
   trainSettings.withSG = withSG;
   trainSettings.wordDelimiterChars = wordDelimiterChars;
   trainSettings.endOfSentenceChars = endOfSentenceChars;
-
-  std::string trainFile;
-  trainFile = fileTrain;
+  trainSettings.minN = minN;
+  trainSettings.maxN = maxN;
+  trainSettings.buckets = buckets;
 
   std::string modelFile;
   modelFile = fileModel;
 
-  std::string stopWordsFile;
-  stopWordsFile = fileStopWords;
+  w2v::w2vModel_t::vocabularyProgressCallback_t vocabularyProgress = nullptr;
+  if (progress.vocabularyProgress != nullptr)
+  {
+    vocabularyProgress = [progress](float _percent)
+    {
+      progress.vocabularyProgress(progress.handle, _percent);
+    };
+  }
 
-  if (verbose)
+  w2v::w2vModel_t::vocabularyStatsCallback_t vocabularyStats = nullptr;
+  if (progress.vocabularyStats != nullptr)
   {
-    std::cout << "Train data file: " << trainFile << std::endl;
-    std::cout << "Output model file: " << modelFile << std::endl;
-    std::cout << "Stop-words file: " << stopWordsFile << std::endl;
-    std::cout << "Training model: " << (trainSettings.withSG ? "Skip-Gram" : "CBOW") << std::endl;
-    std::cout << "Sample approximation method: ";
-    if (trainSettings.withHS)
+    vocabularyStats = [progress](std::size_t _vocWords, std::size_t _trainWords, std::size_t _totalWords)
     {
-      std::cout << "Hierarchical softmax" << std::endl;
+      progress.vocabularyStats(progress.handle, _vocWords, _trainWords, _totalWords);
+    };
+  }
+
+  w2v::w2vModel_t::trainProgressCallback_t trainProgress = nullptr;
+  if (progress.trainProgress != nullptr)
+  {
+    trainProgress = [progress](float _alpha, float _percent)
+    {
+      progress.trainProgress(progress.handle, _alpha, _percent);
+    };
+  }
+
+  auto h = reinterpret_cast<H *>(fd);
+
+  std::shared_ptr<w2v::mapper_t> trainWordsMapper;
+  std::shared_ptr<w2v::mapper_t> stopWordsMapper;
+  try
+  {
+    trainWordsMapper = mapper(train);
+    stopWordsMapper = mapper(stopWords);
+  }
+  catch (const std::exception &e)
+  {
+    h->errMsg = e.what();
+    return 0;
+  }
+
+  if (resume.fileModel != nullptr && resume.fileModel[0] != '\0')
+  {
+    // raw vectors of the model are the starting point of the training
+    if (!h->model->load(resume.fileModel, false))
+    {
+      return 0;
     }
-    else
+
+    std::unordered_map<std::string, std::size_t> frequencies;
+    const char *word = resume.vocabulary.buf;
+    for (auto i = size_t(0); i < resume.vocabulary.size; i++)
     {
-      std::cout << "Negative sampling with number of negative examples = "
-                << static_cast<int>(trainSettings.negative) << std::endl;
+      frequencies[word] = *(resume.vocabulary.freq + i);
+      word += std::strlen(word) + 1;
     }
-    std::cout << "Number of training threads: " << static_cast<int>(trainSettings.threads) << std::endl;
-    std::cout << "Number of training iterations: " << static_cast<int>(trainSettings.iterations) << std::endl;
-    std::cout << "Min word frequency: " << static_cast<int>(trainSettings.minWordFreq) << std::endl;
-    std::cout << "Vector size: " << static_cast<int>(trainSettings.size) << std::endl;
-    std::cout << "Max skip length: " << static_cast<int>(trainSettings.window) << std::endl;
-    std::cout << "Threshold for occurrence of words: " << trainSettings.sample << std::endl;
-    std::cout << "Starting learning rate: " << trainSettings.alpha << std::endl;
-    std::cout << std::endl
-              << std::flush;
-  }
 
-  auto h = new H();
-  bool trained;
-  if (verbose)
-  {
-    trained = h->model->train(
-        trainSettings, trainFile, stopWordsFile,
-        [](float _percent)
-        {
-          std::cout << "\rParsing train data... "
-                    << std::fixed << std::setprecision(2)
-                    << _percent << "%" << std::flush;
-        },
-        [](std::size_t _vocWords, std::size_t _trainWords, std::size_t _totalWords)
-        {
-          std::cout << std::endl
-                    << "Vocabulary size: " << _vocWords << std::endl
-                    << "Train words: " << _trainWords << std::endl
-                    << "Total words: " << _totalWords << std::endl
-                    << std::endl;
-        },
-        [](float _alpha, float _percent)
-        {
-          std::cout << "\r                                                                  \r"
-                    << "alpha: "
-                    << std::fixed << std::setprecision(6)
-                    << _alpha
-                    << ", progress: "
-                    << std::fixed << std::setprecision(2)
-                    << _percent << "%"
-                    << std::flush;
-        });
-    std::cout << std::endl;
-  }
-  else
-  {
-    trained = h->model->train(trainSettings, trainFile, stopWordsFile, nullptr, nullptr, nullptr);
-  }
-  if (!trained)
-  {
-    std::cerr << "Training failed: " << h->model->errMsg() << std::endl;
+    std::string weightsFile;
+    if (resume.fileWeights != nullptr)
+    {
+      weightsFile = resume.fileWeights;
+    }
+
+    if (!h->model->resume(trainSettings, trainWordsMapper, stopWordsMapper, frequencies, weightsFile, vocabularyProgress, vocabularyStats, trainProgress))
+    {
+      return 0;
+    }
+  }
+  else if (!h->model->train(trainSettings, trainWordsMapper, stopWordsMapper, vocabularyProgress, vocabularyStats, trainProgress))
+  {
     return 0;
   }
 
   if (!h->model->save(modelFile))
   {
-    std::cerr << "Model file saving failed: " << h->model->errMsg() << std::endl;
     return 0;
   }
 
-  return h;
+  if (h->model->hasSubwords() && fileSubwords != nullptr && fileSubwords[0] != '\0')
+  {
+    if (!h->model->saveSubwords(fileSubwords))
+    {
+      return 0;
+    }
+  }
+
+  if (fileWeights != nullptr && fileWeights[0] != '\0')
+  {
+    if (!h->model->saveWeights(fileWeights))
+    {
+      return 0;
+    }
+  }
+
+  return 1;
+}
+
+void Stop(void *fd)
+{
+  auto h = reinterpret_cast<H *>(fd);
+  h->model->stop();
+}
+
+char *ErrMsg(void *fd)
+{
+  auto h = reinterpret_cast<H *>(fd);
+  if (!h->errMsg.empty())
+  {
+    return strdup(h->errMsg.c_str());
+  }
+  return strdup(h->model->errMsg().c_str());
 }
 
 // Load model
@@ -167,12 +230,47 @@ void Free(void *fd)
   delete h;
 }
 
+size_t VectorSize(void *fd)
+{
+  auto h = reinterpret_cast<H *>(fd);
+  return h->model->vectorSize();
+}
+
+void SetWordDelimiterChars(void *fd, const char *chars)
+{
+  auto h = reinterpret_cast<H *>(fd);
+  h->wordDelimiterChars = chars;
+}
+
+uint8_t LoadSubwords(void *fd, const char *file)
+{
+  auto h = reinterpret_cast<H *>(fd);
+  if (!h->model->loadSubwords(file))
+  {
+    std::cerr << h->model->errMsg() << '\n';
+    return 0;
+  }
+
+  return 1;
+}
+
 float *VectorOf(void *fd, const char *word)
 {
   try
   {
     auto h = reinterpret_cast<H *>(fd);
-    w2v::word2vec_t vec(h->model, word);
+
+    // words out of the vocabulary are composed from their character n-grams
+    w2v::vector_t vec;
+    auto v = h->model->vector(word);
+    if (v != nullptr)
+    {
+      vec = *v;
+    }
+    else if (!h->model->subwordVector(word, vec))
+    {
+      return 0;
+    }
 
     float *vector = (float *)malloc(sizeof(float) * vec.size());
     std::copy(vec.begin(), vec.end(), vector);
@@ -190,7 +288,7 @@ float *Embedding(void *fd, const char *doc)
   try
   {
     auto h = reinterpret_cast<H *>(fd);
-    w2v::doc2vec_t vec(h->model, doc);
+    w2v::doc2vec_t vec(h->model, doc, h->wordDelimiterChars);
 
     float *vector = (float *)malloc(sizeof(float) * vec.size());
     std::copy(vec.begin(), vec.end(), vector);
@@ -203,42 +301,260 @@ float *Embedding(void *fd, const char *doc)
   }
 }
 
+// Packs nearest words into C buffers, there are less than k words when
+// the model vocabulary is smaller than k.
+static struct nearest_t pack(const std::vector<std::pair<std::string, float>> &nearests)
+{
+  size_t size = nearests.size();
+  float *seqd = (float *)malloc(sizeof(float) * size);
+
+  size_t len = 0;
+  for (auto i = size_t(0); i < size; i++)
+  {
+    len += nearests[i].first.length() + 1;
+  }
+  char *seqw = (char *)malloc(len);
+
+  size_t p = 0;
+
+  for (auto i = size_t(0); i < size; i++)
+  {
+    *(seqd + i) = nearests[i].second;
+
+    strcpy(seqw + p, nearests[i].first.c_str());
+
+    p += nearests[i].first.length();
+    *(seqw + p) = '\0';
+
+    p++;
+  }
+  return nearest_t{seqd, size, len, seqw};
+}
+
+// Embeds n documents packed one after another as NUL terminated strings,
+// vectors and status are allocated by the caller for n documents.
+uint8_t EmbedBatch(void *fd, const char *docs, size_t n, float *vectors, size_t vectorSize, uint8_t *status)
+{
+  auto h = reinterpret_cast<H *>(fd);
+  if (vectorSize != h->model->vectorSize())
+  {
+    return 0;
+  }
+
+  const char *doc = docs;
+  for (auto i = size_t(0); i < n; i++)
+  {
+    try
+    {
+      w2v::doc2vec_t vec(h->model, doc, h->wordDelimiterChars);
+      std::copy(vec.begin(), vec.end(), vectors + i * vectorSize);
+      *(status + i) = 1;
+    }
+    catch (const std::exception &e)
+    {
+      *(status + i) = 0;
+    }
+
+    doc += std::strlen(doc) + 1;
+  }
+
+  return 1;
+}
+
 struct nearest_t Lookup(void *fd, const char *query, size_t k)
 {
   try
   {
     auto h = reinterpret_cast<H *>(fd);
-    w2v::doc2vec_t vec(h->model, query);
+    w2v::doc2vec_t vec(h->model, query, h->wordDelimiterChars);
 
     std::vector<std::pair<std::string, float>> nearests;
     h->model->nearest(vec, nearests, k);
 
-    float *seqd = (float *)malloc(sizeof(float) * k);
+    return pack(nearests);
+  }
+  catch (const std::exception &e)
+  {
+    return nearest_t{0, 0, 0, 0};
+  }
+}
+
+struct nearest_t LookupVector(void *fd, const float *vec, size_t size, size_t k)
+{
+  try
+  {
+    auto h = reinterpret_cast<H *>(fd);
+    if (size != h->model->vectorSize())
+    {
+      return nearest_t{0, 0, 0, 0};
+    }
+
+    // normalize vector the same way as the model vectors
+    w2v::vector_t v(std::vector<float>(vec, vec + size));
+    float med = 0.0f;
+    for (auto const &i : v)
+    {
+      med += i * i;
+    }
+    if (med <= 0.0f)
+    {
+      return nearest_t{0, 0, 0, 0};
+    }
+    med = std::sqrt(med / v.size());
+    for (auto &i : v)
+    {
+      i /= med;
+    }
+
+    // keep the exact matches, like the words of an analogy, the callers
+    // exclude the words they don't want
+    std::vector<std::pair<std::string, float>> nearests;
+    h->model->nearest(v, nearests, k, 0.0f, 1.1f);
+
+    return pack(nearests);
+  }
+  catch (const std::exception &e)
+  {
+    return nearest_t{0, 0, 0, 0};
+  }
+}
+
+struct words_t Words(void *fd)
+{
+  try
+  {
+    auto h = reinterpret_cast<H *>(fd);
+    auto const &words = h->model->words();
+    auto const &frequencies = h->model->frequencies();
+
+    size_t size = words.size();
+    uint64_t *freq = (uint64_t *)malloc(sizeof(uint64_t) * size);
 
     size_t len = 0;
-    for (auto i = size_t(0); i < k; i++)
+    for (auto const &w : words)
     {
-      len += nearests[i].first.length() + 1;
+      len += w.length() + 1;
     }
-    char *seqw = (char *)malloc(len);
+    char *buf = (char *)malloc(len);
 
     size_t p = 0;
-
-    for (auto i = size_t(0); i < k; i++)
+    for (auto i = size_t(0); i < size; i++)
     {
-      *(seqd + i) = nearests[i].second;
+      *(freq + i) = frequencies[i];
 
-      strcpy(seqw + p, nearests[i].first.c_str());
+      std::memcpy(buf + p, words[i].c_str(), words[i].length() + 1);
+      p += words[i].length() + 1;
+    }
+    return words_t{freq, size, len, buf};
+  }
+  catch (const std::exception &e)
+  {
+    return words_t{0, 0, 0, 0};
+  }
+}
 
-      p += nearests[i].first.length();
-      *(seqw + p) = '\0';
+// Document index, vectors of documents are calculated by the word model fd
+void *NewDocIndex(void *fd)
+{
+  auto h = reinterpret_cast<H *>(fd);
+  return new w2v::d2vModel_t(h->model->vectorSize());
+}
+
+void *LoadDocIndex(void *fd, const char *file)
+{
+  auto h = reinterpret_cast<H *>(fd);
+  auto d = new w2v::d2vModel_t(h->model->vectorSize());
+
+  if (!d->load(file))
+  {
+    std::cerr << d->errMsg() << '\n';
+    delete d;
+    return 0;
+  }
+
+  if (d->vectorSize() != h->model->vectorSize())
+  {
+    std::cerr << "document index vector size does not match the model" << '\n';
+    delete d;
+    return 0;
+  }
+
+  return d;
+}
+
+void FreeDocIndex(void *dd)
+{
+  auto d = reinterpret_cast<w2v::d2vModel_t *>(dd);
+  delete d;
+}
+
+uint8_t SaveDocIndex(void *dd, const char *file)
+{
+  auto d = reinterpret_cast<w2v::d2vModel_t *>(dd);
+  if (!d->save(file))
+  {
+    std::cerr << d->errMsg() << '\n';
+    return 0;
+  }
+
+  return 1;
+}
+
+size_t DocIndexSize(void *dd)
+{
+  auto d = reinterpret_cast<w2v::d2vModel_t *>(dd);
+  return d->modelSize();
+}
+
+uint8_t DocIndexSet(void *dd, void *fd, uint64_t id, const char *doc)
+{
+  try
+  {
+    auto d = reinterpret_cast<w2v::d2vModel_t *>(dd);
+    auto h = reinterpret_cast<H *>(fd);
+    w2v::doc2vec_t vec(h->model, doc, h->wordDelimiterChars);
+
+    d->set(id, vec);
+
+    return 1;
+  }
+  catch (const std::exception &e)
+  {
+    return 0;
+  }
+}
+
+void DocIndexErase(void *dd, uint64_t id)
+{
+  auto d = reinterpret_cast<w2v::d2vModel_t *>(dd);
+  d->erase(id);
+}
 
-      p++;
+struct docs_t DocIndexLookup(void *dd, void *fd, const char *query, size_t k)
+{
+  try
+  {
+    auto d = reinterpret_cast<w2v::d2vModel_t *>(dd);
+    auto h = reinterpret_cast<H *>(fd);
+    w2v::doc2vec_t vec(h->model, query, h->wordDelimiterChars);
+
+    // documents with the same text as the query are not skipped
+    std::vector<std::pair<std::size_t, float>> nearests;
+    d->nearest(vec, nearests, k, 0.0f, 1.1f);
+
+    size_t size = nearests.size();
+    uint64_t *ids = (uint64_t *)malloc(sizeof(uint64_t) * size);
+    float *seqd = (float *)malloc(sizeof(float) * size);
+
+    for (auto i = size_t(0); i < size; i++)
+    {
+      *(ids + i) = nearests[i].first;
+      *(seqd + i) = nearests[i].second;
     }
-    return nearest_t{seqd, len, seqw};
+    return docs_t{ids, seqd, size};
   }
   catch (const std::exception &e)
   {
-    return nearest_t{0, 0, 0};
+    return docs_t{0, 0, 0};
   }
 }
@@ -20,7 +20,8 @@ namespace w2v
     //
     //
 
-    w2vModel_t::w2vModel_t() : model_t<std::string>() {}
+    w2vModel_t::w2vModel_t() : model_t<std::string>(), m_words(), m_frequencies(),
+                               m_stop(new std::atomic<bool>(false)), m_subwords(), m_ngrams(), m_weights() {}
 
     bool w2vModel_t::train(const trainSettings_t &_trainSettings,
                            const std::string &_trainFile,
@@ -32,36 +33,198 @@ namespace w2v
         try
         {
             // map train data set file to memory
-            std::shared_ptr<fileMapper_t> trainWordsMapper(new fileMapper_t(_trainFile));
+            std::shared_ptr<mapper_t> trainWordsMapper(new fileMapper_t(_trainFile));
             // map stop-words file to memory
-            std::shared_ptr<fileMapper_t> stopWordsMapper;
+            std::shared_ptr<mapper_t> stopWordsMapper;
             if (!_stopWordsFile.empty())
             {
                 stopWordsMapper.reset(new fileMapper_t(_stopWordsFile));
             }
 
+            return train(_trainSettings, trainWordsMapper, stopWordsMapper,
+                         _vocabularyProgressCallback, _vocabularyStatsCallback, _trainProgressCallback);
+        }
+        catch (const std::exception &_e)
+        {
+            m_errMsg = _e.what();
+        }
+        catch (...)
+        {
+            m_errMsg = "unknown error";
+        }
+
+        return false;
+    }
+
+    bool w2vModel_t::train(const trainSettings_t &_trainSettings,
+                           const std::shared_ptr<mapper_t> &_trainWordsMapper,
+                           const std::shared_ptr<mapper_t> &_stopWordsMapper,
+                           vocabularyProgressCallback_t _vocabularyProgressCallback,
+                           vocabularyStatsCallback_t _vocabularyStatsCallback,
+                           trainProgressCallback_t _trainProgressCallback) noexcept
+    {
+        m_map.clear();
+
+        return train(_trainSettings, _trainWordsMapper, _stopWordsMapper,
+                     std::unordered_map<std::string, std::size_t>(), "",
+                     _vocabularyProgressCallback, _vocabularyStatsCallback, _trainProgressCallback);
+    }
+
+    bool w2vModel_t::resume(const trainSettings_t &_trainSettings,
+                            const std::shared_ptr<mapper_t> &_trainWordsMapper,
+                            const std::shared_ptr<mapper_t> &_stopWordsMapper,
+                            const std::unordered_map<std::string, std::size_t> &_frequencies,
+                            const std::string &_weightsFile,
+                            vocabularyProgressCallback_t _vocabularyProgressCallback,
+                            vocabularyStatsCallback_t _vocabularyStatsCallback,
+                            trainProgressCallback_t _trainProgressCallback) noexcept
+    {
+        if (m_map.empty())
+        {
+            m_errMsg = "model is not loaded, nothing to resume";
+            return false;
+        }
+        if (m_vectorSize != _trainSettings.size)
+        {
+            m_errMsg = "vector size does not match the model";
+            return false;
+        }
+        if (_trainSettings.maxN > 0)
+        {
+            m_errMsg = "training of a model with subwords can not be resumed";
+            return false;
+        }
+
+        // words of the model are kept, even if they do not appear in the new train data
+        std::unordered_map<std::string, std::size_t> baseWords;
+        for (auto const &i : m_words)
+        {
+            auto f = _frequencies.find(i);
+            baseWords[i] = (f != _frequencies.end()) ? f->second : 0;
+        }
+
+        return train(_trainSettings, _trainWordsMapper, _stopWordsMapper, baseWords, _weightsFile,
+                     _vocabularyProgressCallback, _vocabularyStatsCallback, _trainProgressCallback);
+    }
+
+    bool w2vModel_t::train(const trainSettings_t &_trainSettings,
+                           const std::shared_ptr<mapper_t> &_trainWordsMapper,
+                           const std::shared_ptr<mapper_t> &_stopWordsMapper,
+                           const std::unordered_map<std::string, std::size_t> &_baseWords,
+                           const std::string &_weightsFile,
+                           vocabularyProgressCallback_t _vocabularyProgressCallback,
+                           vocabularyStatsCallback_t _vocabularyStatsCallback,
+                           trainProgressCallback_t _trainProgressCallback) noexcept
+    {
+        try
+        {
+            if (!_trainWordsMapper || _trainWordsMapper->size() == 0)
+            {
+                throw std::runtime_error("train data is empty, nothing to read");
+            }
+            if (!_weightsFile.empty() && _trainSettings.withHS)
+            {
+                throw std::runtime_error("output layer weights can be reused with negative sampling only");
+            }
+
+            // output layer vectors of the words of the model being resumed
+            std::unique_ptr<w2vModel_t> weights;
+            if (!_weightsFile.empty())
+            {
+                weights.reset(new w2vModel_t());
+                if (!weights->load(_weightsFile, false))
+                {
+                    throw std::runtime_error("weights: " + weights->errMsg());
+                }
+                if (weights->vectorSize() != _trainSettings.size)
+                {
+                    throw std::runtime_error("weights vector size does not match the model");
+                }
+            }
+
             // build vocabulary, skip stop-words and words with frequency < minWordFreq
-            std::shared_ptr<vocabulary_t> vocabulary(new vocabulary_t(trainWordsMapper,
-                                                                      stopWordsMapper,
+            std::shared_ptr<vocabulary_t> vocabulary(new vocabulary_t(_trainWordsMapper,
+                                                                      _stopWordsMapper,
                                                                       _trainSettings.wordDelimiterChars,
                                                                       _trainSettings.endOfSentenceChars,
                                                                       _trainSettings.minWordFreq,
+                                                                      _baseWords,
+                                                                      m_stop,
                                                                       _vocabularyProgressCallback,
                                                                       _vocabularyStatsCallback));
+            if (*m_stop)
+            {
+                throw std::runtime_error("training cancelled");
+            }
             // key words descending ordered by their indexes
             std::vector<std::string> words;
             vocabulary->words(words);
-            m_vectorSize = _trainSettings.size;
-            m_mapSize = vocabulary->size();
+
+            // vectors of the model being resumed are the starting point of the training
+            trainer_t::initCallback_t initCallback = nullptr;
+            if (!_baseWords.empty())
+            {
+                initCallback = [&](std::vector<float> &_matrix, std::vector<float> &_bpWeights)
+                {
+                    for (auto const &i : words)
+                    {
+                        auto index = vocabulary->data(i)->index;
+                        auto v = vector(i);
+                        if (v != nullptr)
+                        {
+                            std::copy(v->begin(), v->end(), &_matrix[index * _trainSettings.size]);
+                        }
+                        if (weights)
+                        {
+                            auto w = weights->vector(i);
+                            if (w != nullptr)
+                            {
+                                std::copy(w->begin(), w->end(), &_bpWeights[index * _trainSettings.size]);
+                            }
+                        }
+                    }
+                };
+            }
 
             // train model
             std::vector<float> _trainMatrix;
+            std::vector<float> bpWeights;
             trainer_t(std::make_shared<trainSettings_t>(_trainSettings),
                       vocabulary,
-                      trainWordsMapper,
-                      _trainProgressCallback)(_trainMatrix);
+                      _trainWordsMapper,
+                      m_stop,
+                      _trainProgressCallback)(_trainMatrix, bpWeights, initCallback);
+            if (*m_stop)
+            {
+                throw std::runtime_error("training cancelled");
+            }
+
+            m_map.clear();
+            m_vectorSize = _trainSettings.size;
+            m_mapSize = vocabulary->size();
+
+            m_weights.clear();
+            if (!_trainSettings.withHS)
+            {
+                m_weights.swap(bpWeights);
+            }
+
+            m_words = words;
+            m_frequencies.clear();
+            for (auto const &i : words)
+            {
+                m_frequencies.push_back(vocabulary->data(i)->frequency);
+            }
+
+            m_subwords.reset();
+            m_ngrams.clear();
+            if (_trainSettings.maxN > 0)
+            {
+                m_subwords.reset(new subwords_t(_trainSettings.minN, _trainSettings.maxN, _trainSettings.buckets));
+            }
 
             std::size_t wordIndex = 0;
+            std::vector<std::size_t> buckets;
             for (auto const &i : words)
             {
                 auto &v = m_map[i];
@@ -69,9 +232,32 @@ namespace w2v
                 std::copy(&_trainMatrix[wordIndex * m_vectorSize],
                           &_trainMatrix[(wordIndex + 1) * m_vectorSize],
                           &v[0]);
+
+                // a word vector is the average of the word and its n-gram vectors
+                if (m_subwords && wordIndex > 0)
+                {
+                    (*m_subwords)(i, buckets);
+                    for (auto const &b : buckets)
+                    {
+                        auto shift = (m_mapSize + b) * m_vectorSize;
+                        for (uint16_t k = 0; k < m_vectorSize; ++k)
+                        {
+                            v[k] += _trainMatrix[shift + k];
+                        }
+                    }
+                    for (auto &k : v)
+                    {
+                        k /= buckets.size() + 1;
+                    }
+                }
                 wordIndex++;
             }
 
+            if (m_subwords)
+            {
+                m_ngrams.assign(_trainMatrix.begin() + m_mapSize * m_vectorSize, _trainMatrix.end());
+            }
+
             return true;
         }
         catch (const std::exception &_e)
@@ -86,48 +272,91 @@ namespace w2v
         return false;
     }
 
+    // writes vectors of the words in the original word2vec format, _vector returns the vector of the word with
+    // the specified index
+    static void saveVectors(const std::string &_file,
+                            const std::vector<std::string> &_words,
+                            uint16_t _vectorSize,
+                            const std::function<const float *(std::size_t)> &_vector)
+    {
+        // save trained data in original word2vec format
+        // file header
+        std::string fileHeader = std::to_string(_words.size()) + " " + std::to_string(_vectorSize) + "\n";
+        // calc output size
+        // header size
+        auto outputSize = static_cast<off_t>(fileHeader.length() * sizeof(char));
+        for (auto const &i : _words)
+        {
+            // size of (word + space char + vector size + size of cartridge return char)
+            outputSize += (i.length() + 2) * sizeof(char) + _vectorSize * sizeof(float);
+        }
+        // write data to the file
+        fileMapper_t output(_file, true, outputSize);
+        char sp = ' ';
+        char cr = '\n';
+        off_t offset = 0;
+        // write file header
+        std::memcpy(reinterpret_cast<void *>(output.data() + offset),
+                    fileHeader.data(), fileHeader.length() * sizeof(char));
+        offset += fileHeader.length() * sizeof(char);
+
+        // write words and their vectors, more frequent words first
+        std::size_t index = 0;
+        for (auto const &i : _words)
+        {
+            auto v = _vector(index++);
+            std::memcpy(reinterpret_cast<void *>(output.data() + offset),
+                        i.data(), i.length() * sizeof(char));
+            offset += i.length() * sizeof(char);
+            std::memcpy(reinterpret_cast<void *>(output.data() + offset), &sp, sizeof(char));
+            offset += sizeof(char);
+
+            auto shift = _vectorSize * sizeof(float);
+            std::memcpy(reinterpret_cast<void *>(output.data() + offset), v, shift);
+            offset += shift;
+
+            std::memcpy(reinterpret_cast<void *>(output.data() + offset), &cr, sizeof(char));
+            offset += sizeof(char);
+        }
+    }
+
     bool w2vModel_t::save(const std::string &_modelFile) const noexcept
     {
         try
         {
-            // save trained data in original word2vec format
-            // file header
-            std::string fileHeader = std::to_string(m_mapSize) + " " + std::to_string(m_vectorSize) + "\n";
-            // calc output size
-            // header size
-            auto outputSize = static_cast<off_t>(fileHeader.length() * sizeof(char));
-            for (auto const &i : m_map)
+            saveVectors(_modelFile, m_words, m_vectorSize, [this](std::size_t _index)
             {
-                // size of (word + space char + vector size + size of cartridge return char)
-                outputSize += (i.first.length() + 2) * sizeof(char) + m_vectorSize * sizeof(float);
-            }
-            // write data to the file
-            fileMapper_t output(_modelFile, true, outputSize);
-            char sp = ' ';
-            char cr = '\n';
-            off_t offset = 0;
-            // write file header
-            std::memcpy(reinterpret_cast<void *>(output.data() + offset),
-                        fileHeader.data(), fileHeader.length() * sizeof(char));
-            offset += fileHeader.length() * sizeof(char);
+                return m_map.at(m_words[_index]).data();
+            });
 
-            // write words and their vectors
-            for (auto const &i : m_map)
-            {
-                std::memcpy(reinterpret_cast<void *>(output.data() + offset),
-                            i.first.data(), i.first.length() * sizeof(char));
-                offset += i.first.length() * sizeof(char);
-                std::memcpy(reinterpret_cast<void *>(output.data() + offset), &sp, sizeof(char));
-                offset += sizeof(char);
+            return true;
+        }
+        catch (const std::exception &_e)
+        {
+            m_errMsg = _e.what();
+        }
+        catch (...)
+        {
+            m_errMsg = "unknown error";
+        }
 
-                auto shift = m_vectorSize * sizeof(float);
-                std::memcpy(reinterpret_cast<void *>(output.data() + offset), i.second.data(), shift);
-                offset += shift;
+        return false;
+    }
 
-                std::memcpy(reinterpret_cast<void *>(output.data() + offset), &cr, sizeof(char));
-                offset += sizeof(char);
+    bool w2vModel_t::saveWeights(const std::string &_weightsFile) const noexcept
+    {
+        try
+        {
+            if (m_weights.size() != m_words.size() * m_vectorSize)
+            {
+                throw std::runtime_error("model has no output layer weights of words");
             }
 
+            saveVectors(_weightsFile, m_words, m_vectorSize, [this](std::size_t _index)
+            {
+                return &m_weights[_index * m_vectorSize];
+            });
+
             return true;
         }
         catch (const std::exception &_e)
@@ -143,10 +372,20 @@ namespace w2v
     }
 
     bool w2vModel_t::load(const std::string &_modelFile) noexcept
+    {
+        return load(_modelFile, true);
+    }
+
+    bool w2vModel_t::load(const std::string &_modelFile, bool _normalize) noexcept
     {
         try
         {
             m_map.clear();
+            m_weights.clear();
+            m_words.clear();
+            m_frequencies.clear();
+            m_subwords.reset();
+            m_ngrams.clear();
 
             // map model file, exception will be thrown on empty file
             fileMapper_t input(_modelFile);
@@ -213,12 +452,21 @@ namespace w2v
                     throw std::runtime_error(wrongFormatErrMsg);
                 }
 
+                // word frequencies are not stored in the model file
+                m_words.push_back(word);
+                m_frequencies.push_back(0);
+
                 // get word's vector
                 auto &v = m_map[word];
                 v.resize(m_vectorSize);
                 std::memcpy(v.data(), input.data() + offset, m_vectorSize * sizeof(float));
                 offset += m_vectorSize * sizeof(float); // vector size
 
+                if (!_normalize)
+                {
+                    continue;
+                }
+
                 // normalize vector
                 float med = 0.0f;
                 for (auto const &j : v)
@@ -250,6 +498,157 @@ namespace w2v
         return false;
     }
 
+    bool w2vModel_t::saveSubwords(const std::string &_subwordsFile) const noexcept
+    {
+        try
+        {
+            if (!m_subwords)
+            {
+                throw std::runtime_error("model has no subwords");
+            }
+
+            // file header: buckets, vector size, min and max n-gram lengths, followed by the bucket vectors
+            std::string fileHeader = std::to_string(m_subwords->buckets()) + " " + std::to_string(m_vectorSize)
+                                     + " " + std::to_string(m_subwords->minN())
+                                     + " " + std::to_string(m_subwords->maxN()) + "\n";
+            auto outputSize = static_cast<off_t>(fileHeader.length() * sizeof(char) + m_ngrams.size() * sizeof(float));
+
+            fileMapper_t output(_subwordsFile, true, outputSize);
+            std::memcpy(output.data(), fileHeader.data(), fileHeader.length() * sizeof(char));
+            std::memcpy(output.data() + fileHeader.length() * sizeof(char), m_ngrams.data(),
+                        m_ngrams.size() * sizeof(float));
+
+            return true;
+        }
+        catch (const std::exception &_e)
+        {
+            m_errMsg = _e.what();
+        }
+        catch (...)
+        {
+            m_errMsg = "unknown error";
+        }
+
+        return false;
+    }
+
+    bool w2vModel_t::loadSubwords(const std::string &_subwordsFile) noexcept
+    {
+        try
+        {
+            m_subwords.reset();
+            m_ngrams.clear();
+
+            fileMapper_t input(_subwordsFile);
+
+            // parse header
+            off_t offset = 0;
+            std::string header;
+            char ch = 0;
+            while ((ch = (*(input.data() + offset))) != '\n')
+            {
+                header += ch;
+                if (++offset >= input.size())
+                {
+                    throw std::runtime_error(wrongFormatErrMsg);
+                }
+            }
+            offset++; // skip '\n' char
+
+            std::size_t buckets = 0;
+            uint16_t vectorSize = 0;
+            int minN = 0;
+            int maxN = 0;
+            try
+            {
+                std::size_t pos = 0;
+                buckets = static_cast<std::size_t>(std::stoll(header, &pos));
+                header = header.substr(pos);
+                vectorSize = static_cast<uint16_t>(std::stoi(header, &pos));
+                header = header.substr(pos);
+                minN = std::stoi(header, &pos);
+                header = header.substr(pos);
+                maxN = std::stoi(header, &pos);
+            }
+            catch (...)
+            {
+                throw std::runtime_error(wrongFormatErrMsg);
+            }
+
+            if (vectorSize != m_vectorSize)
+            {
+                throw std::runtime_error("subwords vector size does not match the model");
+            }
+            if (buckets == 0 || minN <= 0 || minN > maxN || maxN > 255)
+            {
+                throw std::runtime_error(wrongFormatErrMsg);
+            }
+            if (static_cast<off_t>(offset + buckets * vectorSize * sizeof(float)) != input.size())
+            {
+                throw std::runtime_error(wrongFormatErrMsg);
+            }
+
+            m_ngrams.resize(buckets * vectorSize);
+            std::memcpy(m_ngrams.data(), input.data() + offset, m_ngrams.size() * sizeof(float));
+            m_subwords.reset(new subwords_t(static_cast<uint8_t>(minN), static_cast<uint8_t>(maxN), buckets));
+
+            return true;
+        }
+        catch (const std::exception &_e)
+        {
+            m_errMsg = _e.what();
+        }
+        catch (...)
+        {
+            m_errMsg = "model: unknown error";
+        }
+
+        return false;
+    }
+
+    bool w2vModel_t::subwordVector(const std::string &_word, vector_t &_vector) const noexcept
+    {
+        if (!m_subwords)
+        {
+            return false;
+        }
+
+        std::vector<std::size_t> buckets;
+        (*m_subwords)(_word, buckets);
+        if (buckets.empty())
+        {
+            return false;
+        }
+
+        _vector.assign(m_vectorSize, 0.0f);
+        for (auto const &b : buckets)
+        {
+            auto shift = b * m_vectorSize;
+            for (uint16_t k = 0; k < m_vectorSize; ++k)
+            {
+                _vector[k] += m_ngrams[shift + k];
+            }
+        }
+
+        // normalize vector the same way as the model vectors
+        float med = 0.0f;
+        for (auto const &i : _vector)
+        {
+            med += i * i;
+        }
+        if (med <= 0.0f)
+        {
+            return false;
+        }
+        med = std::sqrt(med / _vector.size());
+        for (auto &i : _vector)
+        {
+            i /= med;
+        }
+
+        return true;
+    }
+
     //
     //
     //
@@ -390,6 +789,13 @@ namespace w2v
         if (i != nullptr)
         {
             std::copy(i->begin(), i->end(), begin());
+            return;
+        }
+
+        vector_t v;
+        if (_model->subwordVector(_word, v))
+        {
+            std::copy(v.begin(), v.end(), begin());
         }
     }
 
@@ -404,6 +810,7 @@ namespace w2v
         stringMapper_t stringMapper(_doc);
         wordReader_t<stringMapper_t> wordReader(stringMapper, _wordDelimiterChars, "");
         std::string word;
+        vector_t composed;
         while (wordReader.nextWord(word))
         {
             if (word.empty())
@@ -413,7 +820,11 @@ namespace w2v
             auto next = _model->vector(word);
             if (next == nullptr)
             {
-                continue;
+                if (!_model->subwordVector(word, composed))
+                {
+                    continue;
+                }
+                next = &composed;
             }
             for (uint16_t i = 0; i < _model->vectorSize(); ++i)
             {
@@ -17,10 +17,15 @@
 #include <memory>
 #include <functional>
 #include <cmath>
+#include <atomic>
 #include <stdexcept>
 
+#include "subwords.hpp"
+
 namespace w2v
 {
+    class mapper_t;
+
     /**
      * @brief trainSettings structure holds all training parameters
      */
@@ -38,6 +43,9 @@ namespace w2v
         uint8_t iterations = 5;       ///< train iterations
         float alpha = 0.05f;          ///< starting learn rate
         bool withSG = false;          ///< use Skip-Gram instead of CBOW
+        uint8_t minN = 3;             ///< min length of character n-grams
+        uint8_t maxN = 0;             ///< max length of character n-grams, 0 disables subword vectors
+        uint32_t buckets = 2000000;   ///< number of hash buckets of character n-grams
         std::string wordDelimiterChars = " \n,.-!?:;/\"#$%&'()*+<=>@[]\\^_`{|}~\t\v\f\r";
         std::string endOfSentenceChars = ".\n?!";
         trainSettings_t() = default;
@@ -220,11 +228,13 @@ namespace w2v
          * @param _nearest storage of found nearest vectors ordered descending by distance to specified vector
          * @param _amount max. amount of nearest vectors
          * @param _minDistance min. distance between vectors
+         * @param _maxDistance max. distance between vectors, closer vectors are considered the same and skipped
          */
         inline void nearest(const vector_t &_vec,
                             std::vector<std::pair<key_t, float>> &_nearest,
                             std::size_t _amount,
-                            float _minDistance = 0.0f) const noexcept
+                            float _minDistance = 0.0f,
+                            float _maxDistance = 0.9999f) const noexcept
         {
             assert(m_vectorSize == _vec.size());
 
@@ -239,7 +249,7 @@ namespace w2v
             for (auto const &i : m_map)
             {
                 auto match = distance(_vec, i.second);
-                if ((match > 0.9999f) || (match < _minDistance))
+                if ((match > _maxDistance) || (match < _minDistance))
                 { // 1.0f is not guarantied
                     continue;
                 }
@@ -286,6 +296,14 @@ namespace w2v
         /// type of callback function to be called on training progress events
         using trainProgressCallback_t = std::function<void(float, float)>;
 
+    private:
+        std::vector<std::string> m_words;        ///< words ordered by their indexes (more frequent words first)
+        std::vector<std::size_t> m_frequencies;  ///< word frequencies ordered the same way as m_words
+        std::shared_ptr<std::atomic<bool>> m_stop; ///< training cancellation flag
+        std::unique_ptr<subwords_t> m_subwords;  ///< character n-grams hashing, nullptr if there are no subwords
+        std::vector<float> m_ngrams;             ///< n-gram bucket vectors, one after another
+        std::vector<float> m_weights;            ///< output layer vectors of the words trained with negative sampling
+
     public:
         /// Constructs w2vModel object
         w2vModel_t(); //: model_t<std::string>() {}
@@ -301,7 +319,7 @@ namespace w2v
          * nullptr if train data corpus statistic is not needed
          * @param _trainProgressCallback callback function reporting training progress,
          * nullptr if training progress statistic is not needed
-         * @returns true on successful completion or false otherwise
+         * @returns true on successful completion or false otherwise, including cancellation by stop()
          */
         bool train(const trainSettings_t &_trainSettings,
                    const std::string &_trainFile,
@@ -310,10 +328,96 @@ namespace w2v
                    vocabularyStatsCallback_t _vocabularyStatsCallback,
                    trainProgressCallback_t _trainProgressCallback) noexcept;
 
+        /**
+         * Trains model from train corpus data and stop words already mapped to memory
+         * @param _trainSettings trainSettings_t structure with training parameters
+         * @param _trainWordsMapper mapper object related to train corpus data
+         * @param _stopWordsMapper mapper object related to stop words, nullptr if there are no stop words
+         * @param _vocabularyProgressCallback callback function reporting train corpus data parsing progress,
+         * nullptr if progress statistic is not needed
+         * @param _vocabularyStatsCallback callback function reporting train corpus statistic,
+         * nullptr if train data corpus statistic is not needed
+         * @param _trainProgressCallback callback function reporting training progress,
+         * nullptr if training progress statistic is not needed
+         * @returns true on successful completion or false otherwise, including cancellation by stop()
+         */
+        bool train(const trainSettings_t &_trainSettings,
+                   const std::shared_ptr<mapper_t> &_trainWordsMapper,
+                   const std::shared_ptr<mapper_t> &_stopWordsMapper,
+                   vocabularyProgressCallback_t _vocabularyProgressCallback,
+                   vocabularyStatsCallback_t _vocabularyStatsCallback,
+                   trainProgressCallback_t _trainProgressCallback) noexcept;
+
+        /**
+         * Continues training of the model loaded without normalization on a new train corpus data. Words of the
+         * new data set with frequency >= minWordFreq extend the vocabulary, the learning rate starts over from
+         * the alpha value of _trainSettings.
+         * @param _trainSettings trainSettings_t structure with training parameters, the vector size must match
+         * @param _trainWordsMapper mapper object related to new train corpus data
+         * @param _stopWordsMapper mapper object related to stop words, nullptr if there are no stop words
+         * @param _frequencies frequencies of the model words in the previous train data sets, missing words
+         * count zero
+         * @param _weightsFile file name of the output layer vectors saved along with the model by saveWeights,
+         * empty to train the output layer from scratch
+         * @param _vocabularyProgressCallback callback function reporting train corpus data parsing progress,
+         * nullptr if progress statistic is not needed
+         * @param _vocabularyStatsCallback callback function reporting train corpus statistic,
+         * nullptr if train data corpus statistic is not needed
+         * @param _trainProgressCallback callback function reporting training progress,
+         * nullptr if training progress statistic is not needed
+         * @returns true on successful completion or false otherwise, including cancellation by stop()
+         */
+        bool resume(const trainSettings_t &_trainSettings,
+                    const std::shared_ptr<mapper_t> &_trainWordsMapper,
+                    const std::shared_ptr<mapper_t> &_stopWordsMapper,
+                    const std::unordered_map<std::string, std::size_t> &_frequencies,
+                    const std::string &_weightsFile,
+                    vocabularyProgressCallback_t _vocabularyProgressCallback,
+                    vocabularyStatsCallback_t _vocabularyStatsCallback,
+                    trainProgressCallback_t _trainProgressCallback) noexcept;
+
+        /// requests the running training to stop as soon as possible, safe to call from any thread
+        inline void stop() noexcept { *m_stop = true; }
+
         /// saves word vectors to file with _modelFile name
         bool save(const std::string &_modelFile) const noexcept override;
-        /// loads word vectors from file with _modelFile name
+        /// loads and normalizes word vectors from file with _modelFile name
         bool load(const std::string &_modelFile) noexcept override;
+        /// loads word vectors from file with _modelFile name, raw vectors are needed to continue training
+        bool load(const std::string &_modelFile, bool _normalize) noexcept;
+        /// saves output layer vectors of the words in the model file format, negative sampling only
+        bool saveWeights(const std::string &_weightsFile) const noexcept;
+
+        /// saves character n-gram bucket vectors to file with _subwordsFile name
+        bool saveSubwords(const std::string &_subwordsFile) const noexcept;
+        /// loads character n-gram bucket vectors from file with _subwordsFile name
+        bool loadSubwords(const std::string &_subwordsFile) noexcept;
+        /// @returns true if the model has character n-gram vectors
+        inline bool hasSubwords() const noexcept { return m_subwords != nullptr; }
+
+        /**
+         * Composes a vector of a word out of the vocabulary from its character n-gram vectors
+         * @param _word word to compose vector for
+         * @param[out] _vector normalized vector of the word
+         * @returns false if the model has no subwords or the vector can not be composed
+         */
+        bool subwordVector(const std::string &_word, vector_t &_vector) const noexcept;
+
+    private:
+        bool train(const trainSettings_t &_trainSettings,
+                   const std::shared_ptr<mapper_t> &_trainWordsMapper,
+                   const std::shared_ptr<mapper_t> &_stopWordsMapper,
+                   const std::unordered_map<std::string, std::size_t> &_baseWords,
+                   const std::string &_weightsFile,
+                   vocabularyProgressCallback_t _vocabularyProgressCallback,
+                   vocabularyStatsCallback_t _vocabularyStatsCallback,
+                   trainProgressCallback_t _trainProgressCallback) noexcept;
+
+    public:
+        /// @returns words ordered by their indexes, the sentence delimiter </s> goes first
+        inline const std::vector<std::string> &words() const noexcept { return m_words; }
+        /// @returns word frequencies ordered the same way as words(), all zeros for a loaded model
+        inline const std::vector<std::size_t> &frequencies() const noexcept { return m_frequencies; }
     };
 
     /**
@@ -366,7 +470,8 @@ namespace w2v
     class doc2vec_t : public vector_t
     {
     public:
-        /** Constructs doc2vec object
+        /** Constructs doc2vec object, vectors of words out of the vocabulary are composed from their character
+         * n-grams if the model has them or skipped otherwise
          * @param _model w2vModel_t type object of a pretrained model
          * @param _doc text document to be converted to a vector
          */
@@ -0,0 +1,151 @@
+//
+// Copyright (C) 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/word2vec
+//
+
+package word2vec
+
+/*
+#include <stdint.h>
+#include <stddef.h>
+*/
+import "C"
+import (
+	"fmt"
+	"runtime/cgo"
+	"sync"
+)
+
+// Stage represents the phase a training run is in.
+type Stage int
+
+// Set of stages a training run goes through.
+const (
+	StageParse      Stage = iota + 1 // Parsing the corpus to build the vocabulary.
+	StageVocabulary                  // The vocabulary is built, word counts are known.
+	StageTrain                       // Training the word vectors.
+)
+
+// String implements the fmt.Stringer interface.
+func (s Stage) String() string {
+	switch s {
+	case StageParse:
+		return "parse"
+	case StageVocabulary:
+		return "vocabulary"
+	case StageTrain:
+		return "train"
+	}
+
+	return fmt.Sprintf("Stage(%d)", int(s))
+}
+
+// Progress represents a snapshot of a running training.
+type Progress struct {
+	Stage Stage
+
+	// Percent represents how much of the current stage is complete. For the
+	// train stage it covers all epochs.
+	Percent float32
+
+	// VocabularyWords, TrainWords and TotalWords represent the vocabulary
+	// size, the number of corpus words used for training and the number of
+	// words in the corpus. They are set once the vocabulary stage is reached.
+	VocabularyWords int
+	TrainWords      int
+	TotalWords      int
+
+	// Alpha represents the current learning rate.
+	Alpha float32
+
+	// Epoch represents the current epoch starting from 1 and EpochPercent
+	// how much of that epoch is complete.
+	Epoch        int
+	EpochPercent float32
+}
+
+// =============================================================================
+
+// progress tracks the state of a training run and forwards every update to
+// the configured callback. The C++ train threads report concurrently, so the
+// callback is serialized.
+type progress struct {
+	mu     sync.Mutex
+	config Config
+	report Progress
+}
+
+func (p *progress) update(f func(r *Progress)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f(&p.report)
+
+	if p.config.Progress != nil {
+		p.config.Progress(p.report)
+	}
+
+	if p.config.Verbose {
+		p.print()
+	}
+}
+
+func (p *progress) print() {
+	r := p.report
+
+	switch r.Stage {
+	case StageParse:
+		fmt.Printf("\rParsing train data... %.2f%%", r.Percent)
+
+	case StageVocabulary:
+		fmt.Printf("\nVocabulary size: %d\nTrain words: %d\nTotal words: %d\n\n", r.VocabularyWords, r.TrainWords, r.TotalWords)
+
+	case StageTrain:
+		fmt.Printf("\r%66s\ralpha: %.6f, epoch: %d, progress: %.2f%%", "", r.Alpha, r.Epoch, r.Percent)
+	}
+}
+
+// =============================================================================
+
+//export goVocabularyProgress
+func goVocabularyProgress(handle C.uintptr_t, percent C.float) {
+	p := cgo.Handle(handle).Value().(*progress)
+
+	p.update(func(r *Progress) {
+		r.Stage = StageParse
+		r.Percent = float32(percent)
+	})
+}
+
+//export goVocabularyStats
+func goVocabularyStats(handle C.uintptr_t, vocWords C.size_t, trainWords C.size_t, totalWords C.size_t) {
+	p := cgo.Handle(handle).Value().(*progress)
+
+	p.update(func(r *Progress) {
+		r.Stage = StageVocabulary
+		r.Percent = 100
+		r.VocabularyWords = int(vocWords)
+		r.TrainWords = int(trainWords)
+		r.TotalWords = int(totalWords)
+	})
+}
+
+//export goTrainProgress
+func goTrainProgress(handle C.uintptr_t, alpha C.float, percent C.float) {
+	p := cgo.Handle(handle).Value().(*progress)
+
+	p.update(func(r *Progress) {
+		r.Stage = StageTrain
+		r.Alpha = float32(alpha)
+		r.Percent = min(float32(percent), 100)
+
+		epochs := float32(max(p.config.Learning.Epoch, 1))
+		done := r.Percent / 100 * epochs
+
+		r.Epoch = min(int(done)+1, int(epochs))
+		r.EpochPercent = (done - float32(r.Epoch-1)) * 100
+	})
+}
@@ -11,11 +11,21 @@ package word2vec
 /*
 #include <stdlib.h>
 #include "libw2v/include/w2v.h"
+
+extern void goVocabularyProgress(uintptr_t handle, float percent);
+extern void goVocabularyStats(uintptr_t handle, size_t vocWords, size_t trainWords, size_t totalWords);
+extern void goTrainProgress(uintptr_t handle, float alpha, float percent);
 */
 import "C"
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"runtime"
+	"runtime/cgo"
+	"strings"
 	"unsafe"
 )
 
@@ -24,9 +34,18 @@ type ConfigCorpus struct {
 	// InputFile represents the file of the raw data to process.
 	InputFile string
 
+	// Input represents the raw data to process when InputFile is empty. It is
+	// read to the end and kept in memory for the training. See Sentences and
+	// Tokens to train from sentences produced in memory.
+	Input io.Reader
+
 	// StopWordsFile represents the file of the stopwords to use.
 	StopWordsFile string
 
+	// StopWords represents the set of stopwords to use in memory. They are
+	// skipped along with the ones from StopWordsFile.
+	StopWords []string
+
 	// Tokenizer represents the word delimiter.
 	// Ex: " \n,.-!?:;/\"#$%&'()*+<=>@[]\\^_`{|}~\t\v\f\r"
 	Tokenizer string
@@ -57,6 +76,45 @@ type ConfigWordVector struct {
 	Frequency int
 }
 
+// ConfigSubword represents character n-gram related config items. Subword
+// vectors let the model compose vectors for words out of its vocabulary,
+// like misspellings, from the n-grams they share with known words.
+type ConfigSubword struct {
+	// MinN represents the min length of character n-grams.
+	// Ex: 3
+	MinN int
+
+	// MaxN represents the max length of character n-grams. Subword vectors
+	// are trained only when it is greater than zero.
+	// Ex: 6
+	MaxN int
+
+	// Buckets represents the number of hash buckets character n-grams are
+	// stored in. Every bucket takes a vector, so it drives the memory used.
+	// Ex: 2000000
+	Buckets int
+}
+
+// ConfigResume represents the model a training continues from. The words of
+// the model are kept and the words of the new corpus that pass the Frequency
+// cutoff are added to them. The learning rate starts over from Learning.Rate.
+type ConfigResume struct {
+	// Model represents the file of the model to continue training. The
+	// vector size must match the model.
+	Model string
+
+	// Vocabulary represents the TSV file written along with the model, see
+	// OutputVocabulary. Without it, the words of the model only count their
+	// occurrences in the new corpus.
+	Vocabulary string
+
+	// Weights represents the output layer file written along with the
+	// model, see OutputWeights. Without it, the output layer is trained from
+	// scratch, which moves the existing vectors further. It can be used with
+	// negative sampling only.
+	Weights string
+}
+
 // ConfigLearning represents learning related config items.
 type ConfigLearning struct {
 	// Epoch represents the number of training runs.
@@ -72,7 +130,9 @@ type ConfigLearning struct {
 type Config struct {
 	Corpus   ConfigCorpus
 	Vector   ConfigWordVector
+	Subword  ConfigSubword
 	Learning ConfigLearning
+	Resume   ConfigResume
 
 	// choose of the learning model:
 	//  - Continuous Bag of Words (CBOW)
@@ -92,6 +152,23 @@ type Config struct {
 	Output  string
 	Threads int
 	Verbose bool
+
+	// OutputVocabulary represents the TSV file to write the vocabulary with
+	// word frequencies to. The vocabulary is not written when empty.
+	OutputVocabulary string
+
+	// OutputSubwords represents the file to write the character n-gram
+	// vectors to when Subword.MaxN is set. See Model.LoadSubwords.
+	OutputSubwords string
+
+	// OutputWeights represents the file to write the output layer weights of
+	// the words to, so the training can be continued later without losing
+	// them. See ConfigResume. It can be used with negative sampling only.
+	OutputWeights string
+
+	// Progress is called with a snapshot of the training progress as the
+	// corpus is parsed and the vectors are trained. Calls never overlap.
+	Progress func(Progress)
 }
 
 // NewConfigDefault defines a set of default configuration options.
@@ -107,6 +184,11 @@ func NewConfigDefault() Config {
 			Threshold: 1e-3,
 			Frequency: 5,
 		},
+		Subword: ConfigSubword{
+			MinN:    3,
+			MaxN:    0,
+			Buckets: 2000000,
+		},
 		Learning: ConfigLearning{
 			Epoch: 5,
 			Rate:  0.05,
@@ -123,20 +205,38 @@ func NewConfigDefault() Config {
 
 // =============================================================================
 
-// Train performs a training run and produces a new model.
-func Train(config Config) error {
+// Train performs a training run and produces a new model. Cancelling the
+// context stops the training and no model is written.
+func Train(ctx context.Context, config Config) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	w2v := struct {
 		config Config
 		h      unsafe.Pointer
 	}{
 		config: config,
+		h:      C.New(),
 	}
+	defer C.Free(w2v.h)
+
+	// The corpus and the stop words kept in Go memory are pinned, so the
+	// C++ train threads can read them until the training is over.
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
 
-	dataset := C.CString(w2v.config.Corpus.InputFile)
-	defer C.free(unsafe.Pointer(dataset))
+	dataset, err := corpus(&pinner, w2v.config.Corpus)
+	if err != nil {
+		return err
+	}
+	defer C.free(unsafe.Pointer(dataset.file))
 
-	fileStopWords := C.CString(w2v.config.Corpus.StopWordsFile)
-	defer C.free(unsafe.Pointer(fileStopWords))
+	stopWords, err := stopWordsCorpus(&pinner, w2v.config.Corpus)
+	if err != nil {
+		return err
+	}
+	defer C.free(unsafe.Pointer(stopWords.file))
 
 	fileModel := C.CString(w2v.config.Output)
 	defer C.free(unsafe.Pointer(fileModel))
@@ -157,14 +257,61 @@ func Train(config Config) error {
 	sequencer := C.CString(w2v.config.Corpus.Sequencer)
 	defer C.free(unsafe.Pointer(sequencer))
 
-	verbose := C.uchar(0)
+	if w2v.config.Subword.MaxN > 0 && w2v.config.OutputSubwords == "" {
+		return errors.New("subwords are enabled, but no output subwords file provided")
+	}
+
+	fileSubwords := C.CString(w2v.config.OutputSubwords)
+	defer C.free(unsafe.Pointer(fileSubwords))
+
+	if w2v.config.UseHierarchicalSoftMax && (w2v.config.OutputWeights != "" || w2v.config.Resume.Weights != "") {
+		return errors.New("output layer weights can be used with negative sampling only")
+	}
+
+	if w2v.config.Resume.Model != "" && w2v.config.Subword.MaxN > 0 {
+		return errors.New("training of a model with subwords can not be resumed")
+	}
+
+	fileWeights := C.CString(w2v.config.OutputWeights)
+	defer C.free(unsafe.Pointer(fileWeights))
+
+	base, err := resume(w2v.config.Resume)
+	if err != nil {
+		return err
+	}
+	defer freeResume(base)
+
+	handle := cgo.NewHandle(&progress{config: w2v.config})
+	defer handle.Delete()
+
+	progress := C.struct_progress_t{
+		handle:             C.uintptr_t(handle),
+		vocabularyProgress: (*[0]byte)(C.goVocabularyProgress),
+		vocabularyStats:    (*[0]byte)(C.goVocabularyStats),
+		trainProgress:      (*[0]byte)(C.goTrainProgress),
+	}
+
 	if w2v.config.Verbose {
-		verbose = C.uchar(1)
+		printConfig(w2v.config)
 	}
 
-	w2v.h = C.Train(
+	// Stop the C++ train threads when the context is cancelled. The model
+	// must not be freed before this goroutine is done with it.
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			C.Stop(w2v.h)
+		case <-done:
+		}
+	}()
+
+	trained := C.Train(
+		w2v.h,
 		dataset,
-		fileStopWords,
+		stopWords,
 		fileModel,
 		C.ushort(w2v.config.Vector.Frequency),
 		C.ushort(w2v.config.Vector.Vector),
@@ -178,12 +325,211 @@ func Train(config Config) error {
 		withSG,
 		tokenizer,
 		sequencer,
-		verbose,
+		C.uint8_t(w2v.config.Subword.MinN),
+		C.uint8_t(w2v.config.Subword.MaxN),
+		C.uint32_t(w2v.config.Subword.Buckets),
+		fileSubwords,
+		fileWeights,
+		base,
+		progress,
 	)
 
-	if uintptr(w2v.h) == 0 {
-		return fmt.Errorf("unable to train model")
+	close(done)
+	<-stopped
+
+	if w2v.config.Verbose {
+		fmt.Print("\n")
+	}
+
+	if trained == 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		msg := C.ErrMsg(w2v.h)
+		defer C.free(unsafe.Pointer(msg))
+
+		return fmt.Errorf("unable to train model: %s", C.GoString(msg))
+	}
+
+	if w2v.config.OutputVocabulary != "" {
+		m := Model{h: w2v.h}
+
+		if m.vocab, err = loadVocabulary(w2v.h); err != nil {
+			return err
+		}
+
+		if err := m.SaveVocabulary(w2v.config.OutputVocabulary); err != nil {
+			return fmt.Errorf("save vocabulary: %w", err)
+		}
 	}
 
 	return nil
 }
+
+// corpus describes the train data for the C++ side, either a file or the
+// content read from the input kept in Go memory.
+func corpus(pinner *runtime.Pinner, config ConfigCorpus) (C.struct_corpus_t, error) {
+	c := C.struct_corpus_t{
+		file: C.CString(config.InputFile),
+	}
+
+	if config.InputFile != "" {
+		return c, nil
+	}
+
+	if config.Input == nil {
+		C.free(unsafe.Pointer(c.file))
+		return C.struct_corpus_t{}, errors.New("no input provided")
+	}
+
+	data, err := io.ReadAll(config.Input)
+	if err != nil {
+		C.free(unsafe.Pointer(c.file))
+		return C.struct_corpus_t{}, fmt.Errorf("read input: %w", err)
+	}
+
+	if len(data) == 0 {
+		C.free(unsafe.Pointer(c.file))
+		return C.struct_corpus_t{}, errors.New("input is empty")
+	}
+
+	pinner.Pin(&data[0])
+	c.data = (*C.char)(unsafe.Pointer(&data[0]))
+	c.size = C.size_t(len(data))
+
+	return c, nil
+}
+
+// stopWordsCorpus describes the stop words for the C++ side. In memory stop
+// words are joined with the ones from the file, one word per line.
+func stopWordsCorpus(pinner *runtime.Pinner, config ConfigCorpus) (C.struct_corpus_t, error) {
+	c := C.struct_corpus_t{
+		file: C.CString(config.StopWordsFile),
+	}
+
+	if len(config.StopWords) == 0 {
+		return c, nil
+	}
+
+	var b strings.Builder
+	if config.StopWordsFile != "" {
+		data, err := os.ReadFile(config.StopWordsFile)
+		if err != nil {
+			C.free(unsafe.Pointer(c.file))
+			return C.struct_corpus_t{}, fmt.Errorf("read stop words: %w", err)
+		}
+		b.Write(data)
+		b.WriteString("\n")
+	}
+
+	for _, word := range config.StopWords {
+		b.WriteString(word)
+		b.WriteString("\n")
+	}
+
+	data := []byte(b.String())
+
+	pinner.Pin(&data[0])
+	c.data = (*C.char)(unsafe.Pointer(&data[0]))
+	c.size = C.size_t(len(data))
+
+	return c, nil
+}
+
+// resume describes the model to continue training for the C++ side. The
+// frequencies of the vocabulary are copied to C memory released by
+// freeResume.
+func resume(config ConfigResume) (C.struct_resume_t, error) {
+	if config.Model == "" {
+		return C.struct_resume_t{}, nil
+	}
+
+	var counts map[string]int
+	if config.Vocabulary != "" {
+		var err error
+		if counts, err = readVocabulary(config.Vocabulary); err != nil {
+			return C.struct_resume_t{}, fmt.Errorf("read vocabulary: %w", err)
+		}
+	}
+
+	var size int
+	for word := range counts {
+		size += len(word) + 1
+	}
+
+	r := C.struct_resume_t{
+		fileModel:   C.CString(config.Model),
+		fileWeights: C.CString(config.Weights),
+	}
+
+	if len(counts) == 0 {
+		return r, nil
+	}
+
+	r.vocabulary = C.struct_words_t{
+		freq: (*C.uint64_t)(C.malloc(C.size_t(len(counts) * 8))),
+		size: C.size_t(len(counts)),
+		len:  C.size_t(size),
+		buf:  (*C.char)(C.malloc(C.size_t(size))),
+	}
+
+	freq := unsafe.Slice((*uint64)(unsafe.Pointer(r.vocabulary.freq)), len(counts))
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(r.vocabulary.buf)), size)
+
+	var i, p int
+	for word, count := range counts {
+		freq[i] = uint64(count)
+		p += copy(buf[p:], word)
+		buf[p] = 0
+		p++
+		i++
+	}
+
+	return r, nil
+}
+
+func freeResume(r C.struct_resume_t) {
+	C.free(unsafe.Pointer(r.fileModel))
+	C.free(unsafe.Pointer(r.fileWeights))
+	C.free(unsafe.Pointer(r.vocabulary.freq))
+	C.free(unsafe.Pointer(r.vocabulary.buf))
+}
+
+func printConfig(config Config) {
+	input := config.Corpus.InputFile
+	if input == "" {
+		input = "<memory>"
+	}
+	fmt.Println("Train data file:", input)
+	fmt.Println("Output model file:", config.Output)
+	if config.Resume.Model != "" {
+		fmt.Println("Resumed model file:", config.Resume.Model)
+	}
+	fmt.Println("Stop-words file:", config.Corpus.StopWordsFile)
+
+	model := "CBOW"
+	if config.UseSkipGram {
+		model = "Skip-Gram"
+	}
+	fmt.Println("Training model:", model)
+
+	if config.UseHierarchicalSoftMax {
+		fmt.Println("Sample approximation method: Hierarchical softmax")
+	} else {
+		fmt.Println("Sample approximation method: Negative sampling with number of negative examples =", config.SizeNegativeSampling)
+	}
+
+	fmt.Println("Number of training threads:", config.Threads)
+	fmt.Println("Number of training iterations:", config.Learning.Epoch)
+	fmt.Println("Min word frequency:", config.Vector.Frequency)
+	fmt.Println("Vector size:", config.Vector.Vector)
+	fmt.Println("Max skip length:", config.Vector.Window)
+	fmt.Println("Threshold for occurrence of words:", config.Vector.Threshold)
+	fmt.Println("Starting learning rate:", config.Learning.Rate)
+	if config.Subword.MaxN > 0 {
+		fmt.Printf("Subwords: %d-%d character n-grams in %d buckets\n", config.Subword.MinN, config.Subword.MaxN, config.Subword.Buckets)
+		fmt.Println("Output subwords file:", config.OutputSubwords)
+	}
+	fmt.Print("\n")
+}
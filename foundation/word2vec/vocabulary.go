@@ -0,0 +1,215 @@
+//
+// Copyright (C) 2024 Dmitry Kolesnikov
+//
+// This file may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+// https://github.com/fogfish/word2vec
+//
+
+package word2vec
+
+/*
+#include <stdlib.h>
+#include "libw2v/include/w2v.h"
+*/
+import "C"
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"iter"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// vocabulary holds the words known by a model in rank order along with
+// their frequencies in the training corpus.
+type vocabulary struct {
+	words []string
+	freq  []int
+	rank  map[string]int
+}
+
+// loadVocabulary copies the words and their frequencies from the model.
+func loadVocabulary(h unsafe.Pointer) (vocabulary, error) {
+	type words_t struct {
+		freq *C.uint64_t
+		size C.size_t
+		len  C.size_t
+		buf  *C.char
+	}
+
+	bag := (words_t)(C.Words(h))
+	if bag.freq == nil || bag.buf == nil {
+		return vocabulary{}, errors.New("unable to read vocabulary")
+	}
+	defer C.free(unsafe.Pointer(bag.freq))
+	defer C.free(unsafe.Pointer(bag.buf))
+
+	size := int(bag.size)
+	seqf := unsafe.Slice((*uint64)(unsafe.Pointer(bag.freq)), size)
+	seqw := unsafe.Slice((*C.char)(bag.buf), bag.len)
+
+	voc := vocabulary{
+		words: make([]string, size),
+		freq:  make([]int, size),
+		rank:  make(map[string]int, size),
+	}
+
+	p := 0
+	for i := 0; i < size; i++ {
+		voc.words[i] = C.GoString(&seqw[p])
+		voc.freq[i] = int(seqf[i])
+		voc.rank[voc.words[i]] = i
+		p += len(voc.words[i]) + 1
+	}
+
+	return voc, nil
+}
+
+// =============================================================================
+
+// Size returns the number of words in the model's vocabulary.
+func (m *Model) Size() int {
+	return len(m.vocab.words)
+}
+
+// Words returns an iterator over the vocabulary in rank order, yielding each
+// word with its frequency in the training corpus. The sentence delimiter
+// "</s>" is always ranked first.
+//
+// A model file holds the words but not their frequencies. For a model that
+// was only loaded with Load, every frequency is zero until LoadVocabulary
+// reads them from the file written by SaveVocabulary after training.
+func (m *Model) Words() iter.Seq2[string, int] {
+	return func(yield func(string, int) bool) {
+		for i, word := range m.vocab.words {
+			if !yield(word, m.vocab.freq[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Contains reports whether the word is part of the model's vocabulary.
+func (m *Model) Contains(word string) bool {
+	_, exists := m.vocab.rank[word]
+	return exists
+}
+
+// Frequency returns the number of times the word occurred in the training
+// corpus or zero if the word or its frequency is unknown.
+//
+// A model file holds no frequencies, so Frequency returns zero for every
+// word of a model that was only loaded with Load, until LoadVocabulary is
+// called. Rank is known either way, since the file keeps the words ranked.
+func (m *Model) Frequency(word string) int {
+	i, exists := m.vocab.rank[word]
+	if !exists {
+		return 0
+	}
+
+	return m.vocab.freq[i]
+}
+
+// Rank returns the position of the word in the vocabulary, where the most
+// frequent words have the lowest rank. It returns -1 for an unknown word.
+func (m *Model) Rank(word string) int {
+	i, exists := m.vocab.rank[word]
+	if !exists {
+		return -1
+	}
+
+	return i
+}
+
+// SaveVocabulary writes the vocabulary in rank order to a TSV file, one word
+// and its frequency per line.
+func (m *Model) SaveVocabulary(fileVocab string) error {
+	f, err := os.Create(fileVocab)
+	if err != nil {
+		return fmt.Errorf("create file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for word, freq := range m.Words() {
+		fmt.Fprintf(w, "%s\t%d\n", word, freq)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadVocabulary reads word frequencies from a TSV file written by
+// SaveVocabulary and ranks the model's words by them. Words missing from the
+// file keep a zero frequency.
+func (m *Model) LoadVocabulary(fileVocab string) error {
+	counts, err := readVocabulary(fileVocab)
+	if err != nil {
+		return err
+	}
+
+	voc := m.vocab
+	for i, word := range voc.words {
+		voc.freq[i] = counts[word]
+	}
+
+	sort.Stable(byFrequency(voc))
+
+	for i, word := range voc.words {
+		voc.rank[word] = i
+	}
+
+	return nil
+}
+
+// readVocabulary reads the word frequencies from a TSV file written by
+// SaveVocabulary.
+func readVocabulary(fileVocab string) (map[string]int, error) {
+	f, err := os.Open(fileVocab)
+	if err != nil {
+		return nil, fmt.Errorf("open file: %w", err)
+	}
+	defer f.Close()
+
+	counts := make(map[string]int)
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		word, count, found := strings.Cut(scanner.Text(), "\t")
+		if !found {
+			return nil, fmt.Errorf("line %d: missing frequency", line)
+		}
+
+		freq, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		counts[word] = freq
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+
+	return counts, nil
+}
+
+// byFrequency sorts the vocabulary from the more frequent to the less
+// frequent words.
+type byFrequency vocabulary
+
+func (v byFrequency) Len() int           { return len(v.words) }
+func (v byFrequency) Less(i, j int) bool { return v.freq[i] > v.freq[j] }
+func (v byFrequency) Swap(i, j int) {
+	v.words[i], v.words[j] = v.words[j], v.words[i]
+	v.freq[i], v.freq[j] = v.freq[j], v.freq[i]
+}
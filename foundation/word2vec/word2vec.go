@@ -18,6 +18,7 @@ import "C"
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"unsafe"
 )
 
@@ -29,20 +30,23 @@ type Nearest struct {
 
 // =============================================================================
 
-// Model represents a word2vec model.
+// Model represents a word2vec model. Once loaded, a Model is safe for
+// concurrent use by multiple goroutines, except for SetTokenizer,
+// LoadVocabulary, LoadSubwords and Close which must not run concurrently with
+// any other method.
 type Model struct {
 	fileModel  string
 	vectorSize int
 	h          unsafe.Pointer
+	vocab      vocabulary
 }
 
-// Loads takes a file on disk and loads it for processing.
+// Loads takes a file on disk and loads it for processing. The vector size is
+// read from the model file. A vector size of zero accepts it, any other
+// vector size is checked against it and Load returns an error when they
+// differ, rather than reading the vectors with the wrong size.
 func Load(fileModel string, vector int) (w2v Model, err error) {
 	w2v.fileModel = fileModel
-	w2v.vectorSize = 300
-	if vector != 0 {
-		w2v.vectorSize = vector
-	}
 
 	name := C.CString(w2v.fileModel)
 	defer C.free(unsafe.Pointer(name))
@@ -52,10 +56,64 @@ func Load(fileModel string, vector int) (w2v Model, err error) {
 		return w2v, fmt.Errorf("unable to load model")
 	}
 
+	w2v.vectorSize = int(C.VectorSize(w2v.h))
+	if vector != 0 && vector != w2v.vectorSize {
+		w2v.Close()
+		return w2v, fmt.Errorf("vector size %d, the model has %d", vector, w2v.vectorSize)
+	}
+
+	w2v.vocab, err = loadVocabulary(w2v.h)
+	if err != nil {
+		w2v.Close()
+		return w2v, err
+	}
+
 	return w2v, nil
 }
 
-// VectorOf calculates embedding vector for input term (word)
+// Close releases the memory held by the model. The model and the document
+// indexes built on it must not be used afterwards.
+func (m *Model) Close() {
+	if m.h != nil {
+		C.Free(m.h)
+		m.h = nil
+	}
+}
+
+// VectorSize returns the number of data points of the vectors.
+func (m *Model) VectorSize() int {
+	return m.vectorSize
+}
+
+// SetTokenizer replaces the word delimiters used to split documents and
+// queries into words, which defaults to the delimiters of NewConfigDefault.
+// It should match the Tokenizer the model was trained with and must be called
+// before the model is used by multiple goroutines.
+func (m *Model) SetTokenizer(tokenizer string) {
+	chars := C.CString(tokenizer)
+	defer C.free(unsafe.Pointer(chars))
+
+	C.SetWordDelimiterChars(m.h, chars)
+}
+
+// LoadSubwords loads the character n-gram vectors written along with the model
+// when it was trained with subwords. From then on, vectors of words out of the
+// vocabulary are composed from their n-grams by VectorOf, Embedding, Lookup
+// and the document index, so a misspelled word still gets a usable vector.
+func (m *Model) LoadSubwords(fileSubwords string) error {
+	name := C.CString(fileSubwords)
+	defer C.free(unsafe.Pointer(name))
+
+	if C.LoadSubwords(m.h, name) == 0 {
+		return fmt.Errorf("unable to load subwords")
+	}
+
+	return nil
+}
+
+// VectorOf calculates embedding vector for input term (word). The vector of a
+// word out of the vocabulary is composed from its character n-grams when
+// subwords are loaded, otherwise an error is returned.
 func (m *Model) VectorOf(word string, vector []float32) error {
 	cword := C.CString(word)
 	defer C.free(unsafe.Pointer(cword))
@@ -93,36 +151,225 @@ func (m *Model) Embedding(doc string, vector []float32) error {
 	return nil
 }
 
-// Lookup nearest words from the model
+// EmbedBatch calculates the embeddings for a batch of documents with a single
+// call into the model. The error for a document is set when none of its words
+// are known to the model, or when it holds a NUL byte, which separates the
+// documents passed to the model. Its embedding is nil then.
+func (m *Model) EmbedBatch(docs []string) ([][]float32, []error) {
+	errs := make([]error, len(docs))
+	if len(docs) == 0 {
+		return nil, errs
+	}
+
+	var size int
+	for _, doc := range docs {
+		size += len(doc) + 1
+	}
+
+	// A document with a NUL byte is passed as an empty one, so the
+	// documents after it keep their place.
+	buf := make([]byte, 0, size)
+	for i, doc := range docs {
+		if strings.IndexByte(doc, 0) >= 0 {
+			errs[i] = errors.New("document contains a NUL byte")
+			doc = ""
+		}
+
+		buf = append(buf, doc...)
+		buf = append(buf, 0)
+	}
+
+	data := make([]float32, len(docs)*m.vectorSize)
+	status := make([]C.uint8_t, len(docs))
+
+	ok := C.EmbedBatch(
+		m.h,
+		(*C.char)(unsafe.Pointer(&buf[0])),
+		C.size_t(len(docs)),
+		(*C.float)(unsafe.Pointer(&data[0])),
+		C.size_t(m.vectorSize),
+		&status[0],
+	)
+
+	if ok == 0 {
+		err := fmt.Errorf("vector size %d does not match the model", m.vectorSize)
+		for i := range errs {
+			errs[i] = err
+		}
+		return make([][]float32, len(docs)), errs
+	}
+
+	vectors := make([][]float32, len(docs))
+	for i := range docs {
+		if errs[i] != nil {
+			continue
+		}
+
+		if status[i] == 0 {
+			errs[i] = errors.New("unknown tokens")
+			continue
+		}
+
+		vectors[i] = data[i*m.vectorSize : (i+1)*m.vectorSize : (i+1)*m.vectorSize]
+	}
+
+	return vectors, errs
+}
+
+// Lookup nearest words from the model. When the vocabulary holds fewer words
+// than len(seq), the remaining elements of seq are set to their zero value.
 func (m *Model) Lookup(query string, seq []Nearest) error {
 	cq := C.CString(query)
 	defer C.free(unsafe.Pointer(cq))
 
-	type nearest_t struct {
-		seq *C.float
-		len C.ulong
-		buf *C.char
+	bag := (nearest_t)(C.Lookup(m.h, cq, C.size_t(len(seq))))
+
+	nearest, err := bag.read()
+	if err != nil {
+		return err
+	}
+
+	n := copy(seq, nearest)
+	clear(seq[n:])
+
+	return nil
+}
+
+// LookupVector returns up to k words nearest to the specified vector in
+// descending order of similarity. Words matching the vector exactly are
+// returned too. Words with a similarity of zero or less, whose vectors point
+// away from it, are never returned, so there may be fewer than k words.
+func (m *Model) LookupVector(vec []float32, k int) ([]Nearest, error) {
+	if len(vec) != m.vectorSize {
+		return nil, fmt.Errorf("vector size %d, expected %d", len(vec), m.vectorSize)
+	}
+
+	if k <= 0 {
+		return nil, nil
+	}
+
+	bag := (nearest_t)(C.LookupVector(m.h, (*C.float)(unsafe.Pointer(&vec[0])), C.size_t(len(vec)), C.size_t(k)))
+
+	return bag.read()
+}
+
+// Analogy returns up to k words nearest to the sum of the positive words minus
+// the sum of the negative words in descending order of similarity. Answering
+// "man is to king as woman is to ?" takes positive = {"king", "woman"} and
+// negative = {"man"}. The words are found like LookupVector finds them, so
+// the query words themselves are often among them, which AnalogyExcluding
+// leaves out.
+func (m *Model) Analogy(positive []string, negative []string, k int) ([]Nearest, error) {
+	vec, err := m.analogy(positive, negative)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.LookupVector(vec, k)
+}
+
+// AnalogyExcluding performs the same search as Analogy, but never returns the
+// positive and negative words themselves.
+func (m *Model) AnalogyExcluding(positive []string, negative []string, k int) ([]Nearest, error) {
+	vec, err := m.analogy(positive, negative)
+	if err != nil {
+		return nil, err
+	}
+
+	if k <= 0 {
+		return nil, nil
+	}
+
+	exclude := make(map[string]struct{}, len(positive)+len(negative))
+	for _, word := range positive {
+		exclude[word] = struct{}{}
+	}
+	for _, word := range negative {
+		exclude[word] = struct{}{}
 	}
 
-	k := len(seq)
-	bag := (nearest_t)(C.Lookup(m.h, cq, C.ulong(k)))
+	nearest, err := m.LookupVector(vec, k+len(exclude))
+	if err != nil {
+		return nil, err
+	}
+
+	seq := make([]Nearest, 0, k)
+	for _, n := range nearest {
+		if _, exists := exclude[n.Word]; exists {
+			continue
+		}
+
+		if len(seq) == k {
+			break
+		}
+
+		seq = append(seq, n)
+	}
 
+	return seq, nil
+}
+
+func (m *Model) analogy(positive []string, negative []string) ([]float32, error) {
+	if len(positive) == 0 && len(negative) == 0 {
+		return nil, errors.New("no words provided")
+	}
+
+	vec := make([]float32, m.vectorSize)
+	word := make([]float32, m.vectorSize)
+
+	for _, w := range positive {
+		if err := m.VectorOf(w, word); err != nil {
+			return nil, fmt.Errorf("%s: %w", w, err)
+		}
+
+		for i := range vec {
+			vec[i] += word[i]
+		}
+	}
+
+	for _, w := range negative {
+		if err := m.VectorOf(w, word); err != nil {
+			return nil, fmt.Errorf("%s: %w", w, err)
+		}
+
+		for i := range vec {
+			vec[i] -= word[i]
+		}
+	}
+
+	return vec, nil
+}
+
+// =============================================================================
+
+// nearest_t mirrors the C struct returned by the lookup functions.
+type nearest_t struct {
+	seq  *C.float
+	size C.size_t
+	len  C.size_t
+	buf  *C.char
+}
+
+// read copies the nearest words into Go memory and releases the C buffers.
+func (bag nearest_t) read() ([]Nearest, error) {
 	if bag.seq == nil || bag.buf == nil {
-		return errors.New("unknown tokens")
+		return nil, errors.New("unknown tokens")
 	}
+	defer C.free(unsafe.Pointer(bag.seq))
+	defer C.free(unsafe.Pointer(bag.buf))
 
-	seqd := unsafe.Slice((*float32)(bag.seq), k)
+	size := int(bag.size)
+	seqd := unsafe.Slice((*float32)(bag.seq), size)
 	seqw := unsafe.Slice((*C.char)(bag.buf), bag.len)
 
+	seq := make([]Nearest, size)
+
 	p := 0
-	for i := 0; i < k; i++ {
+	for i := 0; i < size; i++ {
 		seq[i].Distance = seqd[i]
 		seq[i].Word = C.GoString(&seqw[p])
 		p += len(seq[i].Word) + 1
 	}
 
-	C.free(unsafe.Pointer(bag.seq))
-	C.free(unsafe.Pointer(bag.buf))
-
-	return nil
+	return seq, nil
 }
@@ -4,8 +4,10 @@ go 1.23.0
 
 require (
 	code.sajari.com/docconv/v2 v2.0.0-pre.4
+	github.com/rivo/uniseg v0.4.7
 	github.com/tmc/langchaingo v0.1.12
 	go.mongodb.org/mongo-driver v1.17.1
+	golang.org/x/net v0.30.0
 	golang.org/x/text v0.19.0
 )
 
@@ -33,14 +35,12 @@ require (
 	github.com/pkoukk/tiktoken-go v0.1.7 // indirect
 	github.com/richardlehane/mscfb v1.0.4 // indirect
 	github.com/richardlehane/msoleps v1.0.4 // indirect
-	github.com/rivo/uniseg v0.4.7 // indirect
 	github.com/ssor/bom v0.0.0-20170718123548-6386211fdfcf // indirect
 	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
 	github.com/xdg-go/scram v1.1.2 // indirect
 	github.com/xdg-go/stringprep v1.0.4 // indirect
 	github.com/youmark/pkcs8 v0.0.0-20240726163527-a2c0da244d78 // indirect
 	golang.org/x/crypto v0.28.0 // indirect
-	golang.org/x/net v0.30.0 // indirect
 	golang.org/x/sync v0.8.0 // indirect
 	google.golang.org/protobuf v1.35.1 // indirect
 	sigs.k8s.io/yaml v1.4.0 // indirect